@@ -8,6 +8,14 @@ type Client interface {
 	Generate(ctx context.Context, prompt string, maxTokens int) (string, error)
 }
 
+// StreamingClient is optionally implemented by clients that can deliver a
+// message as it is generated. onToken receives each fragment in order and the
+// full text is returned, as from Generate. Cancelling ctx aborts the stream.
+type StreamingClient interface {
+	Client
+	GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error)
+}
+
 // Provider describes a model plugin: how to construct a client from
 // a model-specific configuration map, and how to interactively setup
 // that configuration for the user (e.g., prompt for API key or download a model).
@@ -30,4 +38,8 @@ type Provider struct {
 	// Variants optionally returns a list of selectable model identifiers/versions
 	// for this provider. If nil, the CLI will prompt for a free-form identifier.
 	Variants func() []string
+
+	// Discover optionally lists the models a configured endpoint serves
+	// (e.g., GET /v1/models). When set, the CLI prefers it over Variants.
+	Discover func(ctx context.Context, config map[string]string) ([]string, error)
 }
@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of provider failure. A *ProviderError wraps one of them, so callers
+// can test with errors.Is(err, ai.ErrAuth) regardless of the provider.
+var (
+	ErrAuth          = errors.New("authentication failed")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrContextLength = errors.New("prompt exceeds the model's context length")
+	ErrServer        = errors.New("provider server error")
+	ErrModelNotFound = errors.New("model not found")
+)
+
+// ProviderError is an error response from a provider's HTTP API.
+type ProviderError struct {
+	Provider string
+	Status   int    // HTTP status code
+	Code     string // provider error code or type, e.g. "insufficient_quota"
+	Message  string // the provider's own message, if any
+	// Kind is one of the Err values above, or nil when the response did
+	// not match any of them.
+	Kind error
+	// RetryAfter is how long the provider asked callers to wait, from the
+	// Retry-After (or retry-after-ms) header; zero when not given.
+	RetryAfter time.Duration
+}
+
+func (e *ProviderError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s error: status %d %s", e.Provider, e.Status, http.StatusText(e.Status))
+	if e.Kind != nil {
+		fmt.Fprintf(&b, " (%v)", e.Kind)
+	}
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	return b.String()
+}
+
+func (e *ProviderError) Unwrap() error { return e.Kind }
+
+// Temporary reports whether repeating the request may succeed: rate limits
+// (but not an exhausted quota) and server errors.
+func (e *ProviderError) Temporary() bool {
+	switch e.Kind {
+	case ErrRateLimited:
+		return e.Code != "insufficient_quota"
+	case ErrServer:
+		return true
+	}
+	return false
+}
+
+// ResponseError reads the body of a non-2xx response and turns it into a
+// *ProviderError. It understands the error JSON of the built-in providers:
+// {"error":{"message","type","code"}} (OpenAI, Azure, OpenRouter and
+// compatible servers), {"type":"error","error":{"type","message"}}
+// (Anthropic), {"error":{"code","message","status"}} (Gemini) and
+// {"error":"..."} (Ollama). The caller still closes the body.
+func ResponseError(provider string, res *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	e := &ProviderError{Provider: provider, Status: res.StatusCode, RetryAfter: retryAfter(res.Header)}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(raw, &body) == nil && len(body.Error) > 0 {
+		var text string
+		var obj struct {
+			Message string          `json:"message"`
+			Type    string          `json:"type"`
+			Code    json.RawMessage `json:"code"`
+			Status  string          `json:"status"`
+		}
+		switch {
+		case json.Unmarshal(body.Error, &text) == nil:
+			e.Message = text
+		case json.Unmarshal(body.Error, &obj) == nil:
+			e.Message = obj.Message
+			// Gemini and OpenRouter put the HTTP status in "code"; only a
+			// textual code says more than the status line.
+			var code string
+			_ = json.Unmarshal(obj.Code, &code)
+			e.Code = firstNonEmpty(code, obj.Status, obj.Type)
+		}
+	}
+	e.Kind = classify(e.Status, e.Code, e.Message)
+	return e
+}
+
+// classify maps a status, code and message to an error kind. Codes and
+// messages win over the status because providers disagree on statuses, e.g.
+// a context overflow is 400 for OpenAI and Anthropic but 413 for some
+// proxies, and Gemini reports a bad key as 400.
+func classify(status int, code, message string) error {
+	c := strings.ToLower(code)
+	m := strings.ToLower(message)
+	switch {
+	case c == "context_length_exceeded" || status == http.StatusRequestEntityTooLarge ||
+		containsAny(m, "context length", "context window", "maximum context", "prompt is too long",
+			"too many tokens", "exceeds the maximum number of tokens"):
+		return ErrContextLength
+	case c == "model_not_found" || c == "not_found_error" || c == "deploymentnotfound":
+		return ErrModelNotFound
+	case status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		containsAny(c, "invalid_api_key", "authentication_error", "permission_error", "unauthenticated", "permission_denied") ||
+		containsAny(m, "api key not valid", "invalid api key", "incorrect api key"):
+		return ErrAuth
+	case status == http.StatusTooManyRequests || c == "rate_limit_error" || c == "resource_exhausted" || c == "insufficient_quota":
+		return ErrRateLimited
+	case status >= 500 || c == "overloaded_error" || c == "unavailable":
+		return ErrServer
+	case status == http.StatusNotFound:
+		return ErrModelNotFound
+	}
+	return nil
+}
+
+// retryAfter parses Retry-After as seconds or an HTTP date, preferring the
+// millisecond retry-after-ms header OpenAI and Azure also send.
+func retryAfter(h http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.ParseFloat(v, 64); err == nil {
+		if s <= 0 {
+			return 0
+		}
+		return time.Duration(s * float64(time.Second))
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
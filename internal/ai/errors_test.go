@@ -0,0 +1,340 @@
+package ai
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseError(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		status    int
+		header    http.Header
+		body      string
+		kind      error
+		code      string
+		message   string
+		temporary bool
+	}{
+		{
+			name:     "openai bad key",
+			provider: "openai",
+			status:   401,
+			body:     `{"error":{"message":"Incorrect API key provided: sk-abc.","type":"invalid_request_error","param":null,"code":"invalid_api_key"}}`,
+			kind:     ErrAuth,
+			code:     "invalid_api_key",
+			message:  "Incorrect API key provided: sk-abc.",
+		},
+		{
+			name:      "openai rate limit",
+			provider:  "openai",
+			status:    429,
+			body:      `{"error":{"message":"Rate limit reached for gpt-4o on requests per min (RPM): Limit 3.","type":"requests","param":null,"code":"rate_limit_exceeded"}}`,
+			kind:      ErrRateLimited,
+			code:      "rate_limit_exceeded",
+			message:   "Rate limit reached for gpt-4o on requests per min (RPM): Limit 3.",
+			temporary: true,
+		},
+		{
+			name:     "openai quota",
+			provider: "openai",
+			status:   429,
+			body:     `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","param":null,"code":"insufficient_quota"}}`,
+			kind:     ErrRateLimited,
+			code:     "insufficient_quota",
+			message:  "You exceeded your current quota, please check your plan and billing details.",
+		},
+		{
+			name:     "openai context length",
+			provider: "openai",
+			status:   400,
+			body:     `{"error":{"message":"This model's maximum context length is 128000 tokens. However, your messages resulted in 130512 tokens.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`,
+			kind:     ErrContextLength,
+			code:     "context_length_exceeded",
+			message:  "This model's maximum context length is 128000 tokens. However, your messages resulted in 130512 tokens.",
+		},
+		{
+			name:     "openai unknown model",
+			provider: "openai",
+			status:   404,
+			body:     "{\"error\":{\"message\":\"The model `gpt-5o` does not exist or you do not have access to it.\",\"type\":\"invalid_request_error\",\"param\":null,\"code\":\"model_not_found\"}}",
+			kind:     ErrModelNotFound,
+			code:     "model_not_found",
+			message:  "The model `gpt-5o` does not exist or you do not have access to it.",
+		},
+		{
+			name:      "openai server error",
+			provider:  "openai",
+			status:    500,
+			body:      `{"error":{"message":"The server had an error while processing your request. Sorry about that!","type":"server_error","param":null,"code":null}}`,
+			kind:      ErrServer,
+			code:      "server_error",
+			message:   "The server had an error while processing your request. Sorry about that!",
+			temporary: true,
+		},
+		{
+			name:     "anthropic bad key",
+			provider: "anthropic",
+			status:   401,
+			body:     `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			kind:     ErrAuth,
+			code:     "authentication_error",
+			message:  "invalid x-api-key",
+		},
+		{
+			name:      "anthropic rate limit",
+			provider:  "anthropic",
+			status:    429,
+			header:    http.Header{"Retry-After": {"7"}},
+			body:      `{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`,
+			kind:      ErrRateLimited,
+			code:      "rate_limit_error",
+			message:   "Number of request tokens has exceeded your per-minute rate limit",
+			temporary: true,
+		},
+		{
+			name:      "anthropic overloaded",
+			provider:  "anthropic",
+			status:    529,
+			body:      `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			kind:      ErrServer,
+			code:      "overloaded_error",
+			message:   "Overloaded",
+			temporary: true,
+		},
+		{
+			name:     "anthropic prompt too long",
+			provider: "anthropic",
+			status:   400,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210345 tokens > 200000 maximum"}}`,
+			kind:     ErrContextLength,
+			code:     "invalid_request_error",
+			message:  "prompt is too long: 210345 tokens > 200000 maximum",
+		},
+		{
+			name:     "anthropic unknown model",
+			provider: "anthropic",
+			status:   404,
+			body:     `{"type":"error","error":{"type":"not_found_error","message":"model: claude-nine"}}`,
+			kind:     ErrModelNotFound,
+			code:     "not_found_error",
+			message:  "model: claude-nine",
+		},
+		{
+			name:     "gemini bad key",
+			provider: "gemini",
+			status:   400,
+			body:     `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"API_KEY_INVALID"}]}}`,
+			kind:     ErrAuth,
+			code:     "INVALID_ARGUMENT",
+			message:  "API key not valid. Please pass a valid API key.",
+		},
+		{
+			name:      "gemini quota",
+			provider:  "gemini",
+			status:    429,
+			body:      `{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}`,
+			kind:      ErrRateLimited,
+			code:      "RESOURCE_EXHAUSTED",
+			message:   "Resource has been exhausted (e.g. check quota).",
+			temporary: true,
+		},
+		{
+			name:     "gemini too many tokens",
+			provider: "gemini",
+			status:   400,
+			body:     `{"error":{"code":400,"message":"The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).","status":"INVALID_ARGUMENT"}}`,
+			kind:     ErrContextLength,
+			code:     "INVALID_ARGUMENT",
+			message:  "The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).",
+		},
+		{
+			name:     "gemini unknown model",
+			provider: "gemini",
+			status:   404,
+			body:     `{"error":{"code":404,"message":"models/gemini-9 is not found for API version v1beta","status":"NOT_FOUND"}}`,
+			kind:     ErrModelNotFound,
+			code:     "NOT_FOUND",
+			message:  "models/gemini-9 is not found for API version v1beta",
+		},
+		{
+			name:      "gemini unavailable",
+			provider:  "gemini",
+			status:    503,
+			body:      `{"error":{"code":503,"message":"The model is overloaded. Please try again later.","status":"UNAVAILABLE"}}`,
+			kind:      ErrServer,
+			code:      "UNAVAILABLE",
+			message:   "The model is overloaded. Please try again later.",
+			temporary: true,
+		},
+		{
+			name:     "azure bad key",
+			provider: "azure-openai",
+			status:   401,
+			body:     `{"error":{"code":"401","message":"Access denied due to invalid subscription key or wrong API endpoint."}}`,
+			kind:     ErrAuth,
+			code:     "401",
+			message:  "Access denied due to invalid subscription key or wrong API endpoint.",
+		},
+		{
+			name:     "azure missing deployment",
+			provider: "azure-openai",
+			status:   404,
+			body:     `{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`,
+			kind:     ErrModelNotFound,
+			code:     "DeploymentNotFound",
+			message:  "The API deployment for this resource does not exist.",
+		},
+		{
+			name:      "azure rate limit",
+			provider:  "azure-openai",
+			status:    429,
+			body:      `{"error":{"code":"429","message":"Requests to the ChatCompletions_Create Operation have exceeded call rate limit. Please retry after 20 seconds."}}`,
+			kind:      ErrRateLimited,
+			code:      "429",
+			message:   "Requests to the ChatCompletions_Create Operation have exceeded call rate limit. Please retry after 20 seconds.",
+			temporary: true,
+		},
+		{
+			name:     "azure content too large",
+			provider: "azure-openai",
+			status:   400,
+			body:     `{"error":{"message":"This model's maximum context length is 8192 tokens.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`,
+			kind:     ErrContextLength,
+			code:     "context_length_exceeded",
+			message:  "This model's maximum context length is 8192 tokens.",
+		},
+		{
+			name:     "ollama missing model",
+			provider: "ollama",
+			status:   404,
+			body:     `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			kind:     ErrModelNotFound,
+			message:  `model "llama9" not found, try pulling it first`,
+		},
+		{
+			name:      "ollama runner crash",
+			provider:  "ollama",
+			status:    500,
+			body:      `{"error":"llama runner process has terminated: signal: killed"}`,
+			kind:      ErrServer,
+			message:   "llama runner process has terminated: signal: killed",
+			temporary: true,
+		},
+		{
+			name:      "proxy html",
+			provider:  "openai-compatible",
+			status:    502,
+			body:      "<html><body>Bad Gateway</body></html>",
+			kind:      ErrServer,
+			temporary: true,
+		},
+		{
+			name:     "proxy entity too large",
+			provider: "openai-compatible",
+			status:   413,
+			body:     "request entity too large",
+			kind:     ErrContextLength,
+		},
+		{
+			name:     "unclassified",
+			provider: "openai",
+			status:   400,
+			body:     `{"error":{"message":"temperature must be at most 2","type":"invalid_request_error","param":"temperature","code":null}}`,
+			code:     "invalid_request_error",
+			message:  "temperature must be at most 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: tt.status, Header: tt.header, Body: io.NopCloser(strings.NewReader(tt.body))}
+			err := ResponseError(tt.provider, res)
+			var pe *ProviderError
+			if !errors.As(err, &pe) {
+				t.Fatalf("ResponseError() = %T, want *ProviderError", err)
+			}
+			if pe.Provider != tt.provider || pe.Status != tt.status {
+				t.Errorf("provider, status = %q, %d, want %q, %d", pe.Provider, pe.Status, tt.provider, tt.status)
+			}
+			if pe.Kind != tt.kind {
+				t.Errorf("Kind = %v, want %v", pe.Kind, tt.kind)
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.kind)
+			}
+			if pe.Code != tt.code {
+				t.Errorf("Code = %q, want %q", pe.Code, tt.code)
+			}
+			if pe.Message != tt.message {
+				t.Errorf("Message = %q, want %q", pe.Message, tt.message)
+			}
+			if got := pe.Temporary(); got != tt.temporary {
+				t.Errorf("Temporary() = %v, want %v", got, tt.temporary)
+			}
+		})
+	}
+}
+
+func TestProviderErrorString(t *testing.T) {
+	err := &ProviderError{Provider: "anthropic", Status: 429, Kind: ErrRateLimited, Message: "slow down"}
+	if got, want := err.Error(), "anthropic error: status 429 Too Many Requests (rate limited): slow down"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	err = &ProviderError{Provider: "ollama", Status: 400}
+	if got, want := err.Error(), "ollama error: status 400 Bad Request"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		min, max time.Duration
+	}{
+		{name: "none"},
+		{name: "seconds", header: http.Header{"Retry-After": {"20"}}, min: 20 * time.Second, max: 20 * time.Second},
+		{name: "fractional seconds", header: http.Header{"Retry-After": {" 1.5 "}}, min: 1500 * time.Millisecond, max: 1500 * time.Millisecond},
+		{name: "zero", header: http.Header{"Retry-After": {"0"}}},
+		{name: "negative", header: http.Header{"Retry-After": {"-3"}}},
+		{name: "garbage", header: http.Header{"Retry-After": {"soon"}}},
+		{
+			name:   "milliseconds win",
+			header: http.Header{"Retry-After": {"20"}, "Retry-After-Ms": {"250"}},
+			min:    250 * time.Millisecond,
+			max:    250 * time.Millisecond,
+		},
+		{
+			name:   "bad milliseconds fall back",
+			header: http.Header{"Retry-After": {"2"}, "Retry-After-Ms": {"x"}},
+			min:    2 * time.Second,
+			max:    2 * time.Second,
+		},
+		{
+			name:   "http date",
+			header: http.Header{"Retry-After": {time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)}},
+			min:    28 * time.Second, // the date has whole seconds
+			max:    30 * time.Second,
+		},
+		{
+			name:   "http date in the past",
+			header: http.Header{"Retry-After": {time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.header {
+				h[http.CanonicalHeaderKey(k)] = v
+			}
+			if got := retryAfter(h); got < tt.min || got > tt.max {
+				t.Errorf("retryAfter(%v) = %v, want %v..%v", tt.header, got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 var (
 	mu       sync.RWMutex
 	registry = map[string]Provider{}
+	// instances maps names added by RegisterInstance to their base provider.
+	instances = map[string]string{}
 )
 
 // Register a model provider under a name (e.g., "gpt4-o", "ollama").
@@ -18,7 +20,34 @@ func Register(name string, c Provider) {
 	registry[name] = c
 }
 
+// RegisterInstance registers name as another configured instance of the
+// provider base, so one provider can be set up several times under
+// different names (e.g. "lmstudio" and "vllm-team" for two OpenAI-compatible
+// servers). Registering the same instance again is a no-op; a name already
+// taken by a provider or by an instance of another provider is an error.
+func RegisterInstance(name, base string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[name]; ok {
+		switch prev, isInstance := instances[name]; {
+		case !isInstance:
+			return fmt.Errorf("model %q: the name is taken by a built-in provider", name)
+		case prev != base:
+			return fmt.Errorf("model %q: already registered as an instance of %q, not %q", name, prev, base)
+		}
+		return nil
+	}
+	p, ok := registry[base]
+	if !ok {
+		return fmt.Errorf("model %q: unknown provider %q", name, base)
+	}
+	registry[name] = p
+	instances[name] = base
+	return nil
+}
+
 // Create builds a Client by name using the provided model-specific configuration map.
+// Requests made through it are retried according to DefaultRetry.
 func Create(name string, config map[string]string) (Client, error) {
 	mu.RLock()
 	c, ok := registry[name]
@@ -26,7 +55,11 @@ func Create(name string, config map[string]string) (Client, error) {
 	if !ok {
 		return nil, fmt.Errorf("unknown model %q; known: %v", name, Known())
 	}
-	return c.Constructor(config)
+	client, err := c.Constructor(config)
+	if err != nil {
+		return nil, err
+	}
+	return WithRetry(client, DefaultRetry), nil
 }
 
 // Known returns the registered model names.
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRegisterInstance(t *testing.T) {
+	Register("factory-a", Provider{Constructor: func(map[string]string) (Client, error) { return stubClient("a"), nil }})
+	Register("factory-b", Provider{Constructor: func(map[string]string) (Client, error) { return stubClient("b"), nil }})
+
+	if err := RegisterInstance("factory-team", "factory-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterInstance("factory-team", "factory-a"); err != nil {
+		t.Errorf("registering the same instance again = %v", err)
+	}
+	c, err := Create("factory-team", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.Generate(context.Background(), "", 0); got != "a" {
+		t.Errorf("factory-team generated %q, want factory-a's %q", got, "a")
+	}
+
+	tests := []struct {
+		name, base, err string
+	}{
+		{"factory-team", "factory-b", `model "factory-team": already registered as an instance of "factory-a", not "factory-b"`},
+		{"factory-b", "factory-a", `model "factory-b": the name is taken by a built-in provider`},
+		{"factory-typo", "factory-c", `model "factory-typo": unknown provider "factory-c"`},
+	}
+	for _, tt := range tests {
+		err := RegisterInstance(tt.name, tt.base)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("RegisterInstance(%q, %q) = %v, want %q", tt.name, tt.base, err, tt.err)
+		}
+	}
+	if _, ok := ProviderFor("factory-typo"); ok {
+		t.Error("factory-typo was registered")
+	}
+}
+
+// stubClient answers every prompt with itself.
+type stubClient string
+
+func (c stubClient) Generate(context.Context, string, int) (string, error) { return string(c), nil }
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func init() {
+	ai.Register("anthropic", ai.Provider{
+		Constructor: newAnthropicFromConfig,
+		Setup:       setupAnthropic,
+		Variants:    anthropicVariants,
+	})
+}
+
+const (
+	anthropicEndpoint = "https://api.anthropic.com/v1/messages"
+	anthropicVersion  = "2023-06-01"
+	anthropicModel    = "claude-sonnet-4-5"
+)
+
+func anthropicVariants() []string {
+	return []string{
+		"claude-sonnet-4-5",
+		"claude-haiku-4-5",
+		"claude-opus-4-1",
+	}
+}
+
+// anthropicClient implements ai.Client for the Anthropic Messages API.
+// The endpoint and API version can be overridden per model config, e.g. to
+// go through a proxy.
+type anthropicClient struct {
+	apiKey     string
+	endpoint   string
+	version    string
+	model      string
+	httpClient *http.Client
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicReq struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResp struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+func (c *anthropicClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	if maxTokens <= 0 {
+		maxTokens = 512 // required by the Messages API
+	}
+	body := anthropicReq{
+		Model:  c.model,
+		System: "You are an assistant that writes Conventional Commit messages. Output only the commit message; no code fences.",
+		Messages: []anthropicMessage{
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokens,
+		Temperature: 0.2,
+	}
+	b, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", c.version)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		// overloaded_error (529) is retried by ai.DefaultRetry
+		return "", ai.ResponseError("anthropic", res)
+	}
+
+	var resp anthropicResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("no text from anthropic (stop_reason=%s, input_tokens=%d, output_tokens=%d)",
+			resp.StopReason, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+	}
+	return strings.Join(parts, ""), nil
+}
+
+func newAnthropicFromConfig(config map[string]string) (ai.Client, error) {
+	key := strings.TrimSpace(config["api_key"])
+	if key == "" {
+		return nil, fmt.Errorf("missing Anthropic API key; run 'gessage setup --model anthropic' and paste your key from https://console.anthropic.com/settings/keys")
+	}
+	endpoint := strings.TrimSpace(config["endpoint"])
+	if endpoint == "" {
+		endpoint = anthropicEndpoint
+	}
+	version := strings.TrimSpace(config["version"])
+	if version == "" {
+		version = anthropicVersion
+	}
+	model := strings.TrimSpace(config["model"])
+	if model == "" {
+		model = anthropicModel
+	}
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	return &anthropicClient{apiKey: key, endpoint: endpoint, version: version, model: model, httpClient: httpClient}, nil
+}
+
+// setupAnthropic prompts for the API key and a default model (from variants).
+func setupAnthropic(ctx context.Context) (map[string]string, error) {
+	in := bufio.NewReader(os.Stdin)
+	color.Cyan("Anthropic setup")
+	color.Yellow("1) Visit %s and create an API key.", "https://console.anthropic.com/settings/keys")
+	color.Yellow("2) Paste your key below. Your key will be stored locally in gessage's config file.")
+	fmt.Print(color.HiWhiteString("Anthropic API key: "))
+	key, _ := in.ReadString('\n')
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	variants := anthropicVariants()
+	model := anthropicModel
+	if idx, err := ui.Select("Select a default Claude model:", variants, 0); err == nil && idx >= 0 && idx < len(variants) {
+		model = variants[idx]
+	}
+
+	return map[string]string{
+		"api_key": key,
+		"model":   model,
+	}, nil
+}
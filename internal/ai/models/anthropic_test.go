@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+)
+
+// anthropicServer stands in for the Messages API. Each request is checked
+// and answered by the next entry of replies.
+func anthropicServer(t *testing.T, replies ...func(w http.ResponseWriter)) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "sk-ant-test" {
+			t.Errorf("x-api-key = %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != anthropicVersion {
+			t.Errorf("anthropic-version = %q, want %q", got, anthropicVersion)
+		}
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if s, _ := req["system"].(string); !strings.Contains(s, "Conventional Commit") {
+			t.Errorf("top-level system = %q", s)
+		}
+		if req["model"] != "claude-haiku-4-5" || req["max_tokens"] != float64(256) {
+			t.Errorf("model, max_tokens = %v, %v", req["model"], req["max_tokens"])
+		}
+		msgs, _ := req["messages"].([]any)
+		if len(msgs) != 1 || msgs[0].(map[string]any)["role"] != "user" {
+			t.Errorf("messages = %v; the system prompt must not be a message", msgs)
+		}
+		if calls >= len(replies) {
+			t.Errorf("unexpected request %d", calls+1)
+			http.Error(w, "unexpected request", http.StatusTeapot)
+			return
+		}
+		replies[calls](w)
+		calls++
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func anthropicTestClient(t *testing.T, endpoint string) ai.Client {
+	t.Helper()
+	c, err := newAnthropicFromConfig(map[string]string{
+		"api_key":  "sk-ant-test",
+		"endpoint": endpoint,
+		"model":    "claude-haiku-4-5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func anthropicOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{
+		"content": [
+			{"type": "thinking", "thinking": "hmm"},
+			{"type": "text", "text": "feat: add login\n\n"},
+			{"type": "text", "text": "- add the form"}
+		],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 812, "output_tokens": 14}
+	}`)
+}
+
+func anthropicOverloaded(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(529)
+	fmt.Fprint(w, `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`)
+}
+
+func TestAnthropicGenerate(t *testing.T) {
+	srv, _ := anthropicServer(t, anthropicOK)
+	got, err := anthropicTestClient(t, srv.URL).Generate(context.Background(), "diff", 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "feat: add login\n\n- add the form"; got != want {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+}
+
+func TestAnthropicNoText(t *testing.T) {
+	srv, _ := anthropicServer(t, func(w http.ResponseWriter) {
+		fmt.Fprint(w, `{"content": [], "stop_reason": "max_tokens", "usage": {"input_tokens": 9000, "output_tokens": 256}}`)
+	})
+	_, err := anthropicTestClient(t, srv.URL).Generate(context.Background(), "diff", 256)
+	if err == nil || !strings.Contains(err.Error(), "stop_reason=max_tokens") || !strings.Contains(err.Error(), "output_tokens=256") {
+		t.Errorf("Generate() error = %v; want the stop reason and usage", err)
+	}
+}
+
+func TestAnthropicOverloaded(t *testing.T) {
+	srv, _ := anthropicServer(t, anthropicOverloaded)
+	_, err := anthropicTestClient(t, srv.URL).Generate(context.Background(), "diff", 256)
+	var pe *ai.ProviderError
+	if !errors.As(err, &pe) || pe.Status != 529 || pe.Code != "overloaded_error" || !errors.Is(err, ai.ErrServer) || !pe.Temporary() {
+		t.Fatalf("Generate() error = %#v; want a temporary 529 overloaded_error", err)
+	}
+}
+
+func TestAnthropicRetriesOverloaded(t *testing.T) {
+	srv, calls := anthropicServer(t, anthropicOverloaded, anthropicOK)
+	policy := ai.RetryPolicy{Attempts: 3, Base: time.Millisecond, Max: time.Second}
+	got, err := ai.WithRetry(anthropicTestClient(t, srv.URL), policy).Generate(context.Background(), "diff", 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 2 || !strings.HasPrefix(got, "feat: add login") {
+		t.Errorf("Generate() = %q after %d calls; want the second answer", got, *calls)
+	}
+}
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func init() {
+	ai.Register("azure-openai", ai.Provider{
+		Constructor: newAzureOpenAIFromConfig,
+		Setup:       setupAzureOpenAI,
+	})
+}
+
+const (
+	azureAPIVersion = "2024-10-21"
+	// azureTokenCommand prints an Entra access token for Azure OpenAI.
+	azureTokenCommand = "az account get-access-token --resource https://cognitiveservices.azure.com --query accessToken -o tsv"
+)
+
+// azureOpenAIClient implements ai.Client for an Azure OpenAI deployment.
+// Requests go to <endpoint>/openai/deployments/<deployment>/chat/completions
+// and authenticate with either an api-key header or an Entra bearer token
+// printed by tokenCommand. The token is fetched once and reused for every
+// request of the run, including retries and regenerations, until the API
+// rejects it.
+type azureOpenAIClient struct {
+	url          string
+	deployment   string
+	apiKey       string
+	tokenCommand string
+	httpClient   *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+func (c *azureOpenAIClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var resp openAIResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices from azure-openai")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (c *azureOpenAIClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readChatStream(res.Body, onToken)
+}
+
+// post sends the chat completions request and returns the 2xx response.
+func (c *azureOpenAIClient) post(ctx context.Context, prompt string, maxTokens int, stream bool) (*http.Response, error) {
+	// The deployment selects the model; Azure ignores the field.
+	body := openAIReq{
+		Model: c.deployment,
+		Messages: []openAIMessage{
+			{Role: "system", Content: "You are an assistant that writes Conventional Commit messages. Output only the commit message; no code fences."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokens,
+		Temperature: 0.2,
+		Stream:      stream,
+	}
+
+	b, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	var token string
+	if c.tokenCommand != "" {
+		if token, err = c.bearerToken(ctx); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.Header.Set("api-key", c.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		err := ai.ResponseError("azure-openai", res)
+		if token != "" && errors.Is(err, ai.ErrAuth) {
+			c.dropToken(token) // expired or revoked: the next request fetches a new one
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// bearerToken returns the cached token, running the token command on first
+// use. A failed command is tried again on the next request.
+func (c *azureOpenAIClient) bearerToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" {
+		token, err := azureToken(ctx, c.tokenCommand)
+		if err != nil {
+			return "", err
+		}
+		c.token = token
+	}
+	return c.token, nil
+}
+
+// dropToken forgets token if it is still the cached one, so the token command
+// runs again on the next request.
+func (c *azureOpenAIClient) dropToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// azureToken runs the configured token command through the shell and returns
+// the first line it prints.
+func azureToken(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %v\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	token, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("token command printed no token: %s", command)
+	}
+	return token, nil
+}
+
+func newAzureOpenAIFromConfig(config map[string]string) (ai.Client, error) {
+	endpoint := strings.TrimRight(strings.TrimSpace(config["endpoint"]), "/")
+	deployment := strings.TrimSpace(config["deployment"])
+	if endpoint == "" || deployment == "" {
+		return nil, fmt.Errorf("missing endpoint or deployment for azure-openai; run 'gessage setup --model azure-openai'")
+	}
+	version := strings.TrimSpace(config["api_version"])
+	if version == "" {
+		version = azureAPIVersion
+	}
+	c := &azureOpenAIClient{
+		url:          endpoint + "/openai/deployments/" + url.PathEscape(deployment) + "/chat/completions?api-version=" + url.QueryEscape(version),
+		deployment:   deployment,
+		apiKey:       strings.TrimSpace(config["api_key"]),
+		tokenCommand: strings.TrimSpace(config["token_command"]),
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+	}
+	if c.apiKey == "" && c.tokenCommand == "" {
+		return nil, fmt.Errorf("azure-openai needs an api_key or a token_command; run 'gessage setup --model azure-openai'")
+	}
+	return c, nil
+}
+
+// setupAzureOpenAI prompts for the resource endpoint, deployment, api-version
+// and either an API key or an Entra token command.
+func setupAzureOpenAI(ctx context.Context) (map[string]string, error) {
+	in := bufio.NewReader(os.Stdin)
+	ask := func(label, def string) string {
+		if def != "" {
+			fmt.Print(color.HiWhiteString("%s [%s]: ", label, def))
+		} else {
+			fmt.Print(color.HiWhiteString("%s: ", label))
+		}
+		v, _ := in.ReadString('\n')
+		if v = strings.TrimSpace(v); v == "" {
+			return def
+		}
+		return v
+	}
+
+	color.Cyan("Azure OpenAI setup")
+	color.Yellow("Find the endpoint and keys under your resource's 'Keys and Endpoint' page, and the deployment name in Azure AI Foundry.")
+	endpoint := ask("Resource endpoint (https://<resource>.openai.azure.com)", "")
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	deployment := ask("Deployment name", "")
+	if deployment == "" {
+		return nil, fmt.Errorf("deployment is required")
+	}
+	version := ask("API version", azureAPIVersion)
+
+	cfg := map[string]string{
+		"endpoint":    endpoint,
+		"deployment":  deployment,
+		"api_version": version,
+	}
+	methods := []string{"API key (api-key header)", "Microsoft Entra ID (bearer token from a command)"}
+	idx, err := ui.Select("How should gessage authenticate?", methods, 0)
+	if err != nil {
+		return nil, err
+	}
+	if idx == 1 {
+		cfg["token_command"] = ask("Token command", azureTokenCommand)
+		return cfg, nil
+	}
+	color.Yellow("Your key will be stored locally in gessage's config file.")
+	key := ask("Azure OpenAI API key", "")
+	if key == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	cfg["api_key"] = key
+	return cfg, nil
+}
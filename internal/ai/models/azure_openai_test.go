@@ -0,0 +1,108 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+)
+
+func TestAzureTokenCachedForRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the token command is a POSIX shell script")
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok-123" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if want := "/openai/deployments/gpt-4o-mini/chat/completions"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error": {"code": "ServiceUnavailable", "message": "busy"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "docs: fix typo"}}]}`)
+	}))
+	defer srv.Close()
+
+	// The command logs each run so the test can count them.
+	runs := filepath.Join(t.TempDir(), "runs")
+	c, err := newAzureOpenAIFromConfig(map[string]string{
+		"endpoint":      srv.URL,
+		"deployment":    "gpt-4o-mini",
+		"token_command": "echo run >> '" + runs + "'; echo tok-123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ai.WithRetry(c, ai.RetryPolicy{Attempts: 2, Base: time.Millisecond, Max: time.Second})
+	for i := 0; i < 2; i++ { // a retried request, then a regeneration
+		if got, err := client.Generate(context.Background(), "diff", 128); err != nil || got != "docs: fix typo" {
+			t.Fatalf("Generate() = %q, %v", got, err)
+		}
+	}
+	b, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "run"); n != 1 || calls != 3 {
+		t.Errorf("token command ran %d times for %d requests; want once", n, calls)
+	}
+}
+
+func TestAzureTokenRefetchedAfterAuthError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the token command is a POSIX shell script")
+	}
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		w.Header().Set("Content-Type", "application/json")
+		if auth != "Bearer tok-2" { // the first token has expired
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"code": "401", "message": "Access token has expired or is not yet valid."}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "docs: fix typo"}}]}`)
+	}))
+	defer srv.Close()
+
+	// Each run of the command prints a new token: tok-1, tok-2, ...
+	runs := filepath.Join(t.TempDir(), "runs")
+	c, err := newAzureOpenAIFromConfig(map[string]string{
+		"endpoint":      srv.URL,
+		"deployment":    "gpt-4o-mini",
+		"token_command": "echo run >> '" + runs + "'; echo tok-$(wc -l < '" + runs + "' | tr -d ' ')",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ai.WithRetry(c, ai.RetryPolicy{Attempts: 3, Base: time.Millisecond, Max: time.Second})
+	if _, err := client.Generate(context.Background(), "diff", 128); !errors.Is(err, ai.ErrAuth) {
+		t.Fatalf("Generate() with an expired token = %v, want ErrAuth", err)
+	}
+	for i := 0; i < 2; i++ { // a regeneration, then another one
+		if got, err := client.Generate(context.Background(), "diff", 128); err != nil || got != "docs: fix typo" {
+			t.Fatalf("Generate() after the token was dropped = %q, %v", got, err)
+		}
+	}
+	want := []string{"Bearer tok-1", "Bearer tok-2", "Bearer tok-2"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("requests authorized with %q, want %q", seen, want)
+	}
+}
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func init() {
+	ai.Register("gemini", ai.Provider{
+		Constructor: newGeminiFromConfig,
+		Setup:       setupGemini,
+		Variants:    geminiVariants,
+	})
+}
+
+const (
+	// geminiEndpoint is the API base; requests go to
+	// <endpoint>/models/<model>:generateContent.
+	geminiEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+	geminiModel    = "gemini-2.5-flash"
+	// geminiProThinking caps the thinking of 2.5 Pro, which cannot turn it
+	// off; a commit message needs little reasoning.
+	geminiProThinking = 128
+)
+
+func geminiVariants() []string {
+	return []string{
+		"gemini-2.5-flash",
+		"gemini-2.5-flash-lite",
+		"gemini-2.5-pro",
+	}
+}
+
+// geminiClient implements ai.Client for the Gemini generateContent API.
+type geminiClient struct {
+	apiKey     string
+	endpoint   string
+	model      string
+	httpClient *http.Client
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiReq struct {
+	SystemInstruction geminiContent   `json:"systemInstruction"`
+	Contents          []geminiContent `json:"contents"`
+	GenerationConfig  struct {
+		MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+		Temperature     float32               `json:"temperature"`
+		ThinkingConfig  *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+	} `json:"generationConfig"`
+}
+
+type geminiThinkingConfig struct {
+	ThinkingBudget int `json:"thinkingBudget"`
+}
+
+type geminiResp struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		ThoughtsTokenCount int `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// geminiThinkingBudget returns the thinking budget for model: none for the
+// 2.5 Flash models, a small cap for 2.5 Pro. Other models do not take the
+// setting, reported as false.
+func geminiThinkingBudget(model string) (int, bool) {
+	switch {
+	case strings.HasPrefix(model, "gemini-2.5-flash"):
+		return 0, true
+	case strings.HasPrefix(model, "gemini-2.5-pro"):
+		return geminiProThinking, true
+	}
+	return 0, false
+}
+
+// geminiBlocked lists the finish reasons that mean the answer was withheld
+// rather than finished or cut off.
+var geminiBlocked = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+}
+
+func (c *geminiClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	var body geminiReq
+	body.SystemInstruction = geminiContent{Parts: []geminiPart{{Text: "You are an assistant that writes Conventional Commit messages. Output only the commit message; no code fences."}}}
+	body.Contents = []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}}
+	body.GenerationConfig.MaxOutputTokens = maxTokens
+	body.GenerationConfig.Temperature = 0.2
+	if budget, ok := geminiThinkingBudget(c.model); ok {
+		body.GenerationConfig.ThinkingConfig = &geminiThinkingConfig{ThinkingBudget: budget}
+		// Thinking counts against maxOutputTokens; keep the answer's share
+		if maxTokens > 0 {
+			body.GenerationConfig.MaxOutputTokens += budget
+		}
+	}
+	b, _ := json.Marshal(body)
+
+	u := c.endpoint + "/models/" + url.PathEscape(c.model) + ":generateContent"
+	req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", ai.ResponseError("gemini", res)
+	}
+
+	var resp geminiResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	if r := resp.PromptFeedback.BlockReason; r != "" {
+		return "", fmt.Errorf("gemini blocked the prompt (%s); the diff may contain content its safety filters reject", r)
+	}
+	if len(resp.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates from gemini")
+	}
+	cand := resp.Candidates[0]
+	if geminiBlocked[cand.FinishReason] {
+		return "", fmt.Errorf("gemini withheld the answer (finish reason %s); try another model or a smaller diff", cand.FinishReason)
+	}
+	if cand.FinishReason == "MAX_TOKENS" {
+		return "", fmt.Errorf("gemini hit the output limit of %d tokens (%d spent thinking) before finishing; raise --max-tokens",
+			body.GenerationConfig.MaxOutputTokens, resp.UsageMetadata.ThoughtsTokenCount)
+	}
+	var parts []string
+	for _, p := range cand.Content.Parts {
+		parts = append(parts, p.Text)
+	}
+	text := strings.Join(parts, "")
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("empty answer from gemini (finish reason %s)", cand.FinishReason)
+	}
+	return text, nil
+}
+
+func newGeminiFromConfig(config map[string]string) (ai.Client, error) {
+	key := strings.TrimSpace(config["api_key"])
+	if key == "" {
+		return nil, fmt.Errorf("missing Gemini API key; run 'gessage setup --model gemini' and paste your key from https://aistudio.google.com/apikey")
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(config["endpoint"]), "/")
+	if endpoint == "" {
+		endpoint = geminiEndpoint
+	}
+	model := strings.TrimPrefix(strings.TrimSpace(config["model"]), "models/")
+	if model == "" {
+		model = geminiModel
+	}
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	return &geminiClient{apiKey: key, endpoint: endpoint, model: model, httpClient: httpClient}, nil
+}
+
+// setupGemini prompts for the API key and a default model (from variants).
+func setupGemini(ctx context.Context) (map[string]string, error) {
+	in := bufio.NewReader(os.Stdin)
+	color.Cyan("Gemini setup")
+	color.Yellow("1) Visit %s and create an API key.", "https://aistudio.google.com/apikey")
+	color.Yellow("2) Paste your key below. Your key will be stored locally in gessage's config file.")
+	fmt.Print(color.HiWhiteString("Gemini API key: "))
+	key, _ := in.ReadString('\n')
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	variants := geminiVariants()
+	model := geminiModel
+	if idx, err := ui.Select("Select a default Gemini model:", variants, 0); err == nil && idx >= 0 && idx < len(variants) {
+		model = variants[idx]
+	}
+
+	return map[string]string{
+		"api_key": key,
+		"model":   model,
+	}, nil
+}
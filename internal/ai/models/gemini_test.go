@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// geminiServer stands in for generateContent, records the request body and
+// answers with reply.
+func geminiServer(t *testing.T, model, reply string) (*httptest.Server, *geminiReq) {
+	t.Helper()
+	var got geminiReq
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/models/" + model + ":generateContent"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		if key := r.Header.Get("x-goog-api-key"); key != "test-key" {
+			t.Errorf("x-goog-api-key = %q", key)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func geminiGenerate(t *testing.T, srv *httptest.Server, model string) (string, error) {
+	t.Helper()
+	c, err := newGeminiFromConfig(map[string]string{"api_key": "test-key", "endpoint": srv.URL + "/", "model": "models/" + model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Generate(context.Background(), "diff", 512)
+}
+
+const geminiOK = `{"candidates": [{"content": {"role": "model", "parts": [{"text": "fix: handle nil"}, {"text": " config"}]}, "finishReason": "STOP"}]}`
+
+func TestGeminiThinkingBudget(t *testing.T) {
+	tests := []struct {
+		model     string
+		budget    int
+		thinking  bool
+		maxOutput int
+	}{
+		{"gemini-2.5-flash", 0, true, 512},
+		{"gemini-2.5-flash-lite", 0, true, 512},
+		{"gemini-2.5-pro", geminiProThinking, true, 512 + geminiProThinking},
+		{"gemini-2.0-flash", 0, false, 512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			srv, req := geminiServer(t, tt.model, geminiOK)
+			got, err := geminiGenerate(t, srv, tt.model)
+			if err != nil || got != "fix: handle nil config" {
+				t.Fatalf("Generate() = %q, %v", got, err)
+			}
+			tc := req.GenerationConfig.ThinkingConfig
+			if (tc != nil) != tt.thinking || (tc != nil && tc.ThinkingBudget != tt.budget) {
+				t.Errorf("thinkingConfig = %+v, want budget %d (set: %v)", tc, tt.budget, tt.thinking)
+			}
+			if req.GenerationConfig.MaxOutputTokens != tt.maxOutput {
+				t.Errorf("maxOutputTokens = %d, want %d", req.GenerationConfig.MaxOutputTokens, tt.maxOutput)
+			}
+			if req.SystemInstruction.Parts[0].Text == "" || req.Contents[0].Parts[0].Text != "diff" {
+				t.Errorf("request = %+v", req)
+			}
+		})
+	}
+}
+
+func TestGeminiThinkingBudgetJSON(t *testing.T) {
+	// A zero budget must still be sent: leaving it out means "think freely".
+	var body geminiReq
+	body.GenerationConfig.ThinkingConfig = &geminiThinkingConfig{}
+	b, _ := json.Marshal(body)
+	if !strings.Contains(string(b), `"thinkingConfig":{"thinkingBudget":0}`) {
+		t.Errorf("request JSON = %s", b)
+	}
+}
+
+func TestGeminiFinishReasons(t *testing.T) {
+	tests := []struct {
+		name, reply, wantErr string
+	}{
+		{
+			name:    "max tokens",
+			reply:   `{"candidates": [{"content": {"parts": [{"text": "feat: add"}]}, "finishReason": "MAX_TOKENS"}], "usageMetadata": {"thoughtsTokenCount": 0}}`,
+			wantErr: "output limit of 512 tokens",
+		},
+		{
+			name:    "safety",
+			reply:   `{"candidates": [{"content": {"parts": []}, "finishReason": "SAFETY"}]}`,
+			wantErr: "withheld the answer (finish reason SAFETY)",
+		},
+		{
+			name:    "blocked prompt",
+			reply:   `{"promptFeedback": {"blockReason": "OTHER"}}`,
+			wantErr: "blocked the prompt (OTHER)",
+		},
+		{
+			name:    "empty answer",
+			reply:   `{"candidates": [{"content": {"parts": [{"text": " "}]}, "finishReason": "STOP"}]}`,
+			wantErr: "empty answer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := geminiServer(t, "gemini-2.5-flash", tt.reply)
+			_, err := geminiGenerate(t, srv, "gemini-2.5-flash")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -46,6 +46,31 @@ type ollamaResp struct {
 }
 
 func (c *ollamaClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	res, err := c.post(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var resp ollamaResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	return resp.Response, nil
+}
+
+// GenerateStream reads the newline-delimited JSON Ollama sends with stream: true.
+func (c *ollamaClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	res, err := c.post(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readOllamaStream(res.Body, onToken)
+}
+
+// post sends the generate request and returns the 2xx response.
+func (c *ollamaClient) post(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
 	// Build final prompt and clamp size to avoid server-side truncation noise
 	finalPrompt := "Write a Conventional Commit message ONLY.\n" + prompt
 	if c.maxPromptBytes > 0 && len(finalPrompt) > c.maxPromptBytes {
@@ -56,29 +81,24 @@ func (c *ollamaClient) Generate(ctx context.Context, prompt string, maxTokens in
 	body := ollamaReq{
 		Model:  c.model,
 		Prompt: finalPrompt,
-		Stream: false,
+		Stream: stream,
 	}
 	b, _ := json.Marshal(body)
 	req, err := http.NewRequestWithContext(ctx, "POST", c.host+"/api/generate", bytes.NewBuffer(b))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", fmt.Errorf("ollama error: status %s", res.Status)
+		defer res.Body.Close()
+		return nil, ai.ResponseError("ollama", res)
 	}
-
-	var resp ollamaResp
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return "", err
-	}
-	return resp.Response, nil
+	return res, nil
 }
 
 func newOllamaFromConfig(config map[string]string) (ai.Client, error) {
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func init() {
+	ai.Register("openai-compatible", ai.Provider{
+		Constructor: newCompatibleFromConfig,
+		Setup:       setupCompatible,
+		Discover:    discoverCompatible,
+	})
+}
+
+const (
+	compatibleBaseURL = "http://localhost:1234/v1" // LM Studio's default
+	// compatibleHeaderPrefix marks config keys sent as extra request
+	// headers, e.g. "header.X-Team": "platform".
+	compatibleHeaderPrefix = "header."
+)
+
+// compatibleClient implements ai.Client for any server that speaks the
+// OpenAI chat completions API: vLLM, LM Studio, llama.cpp, LocalAI and
+// hosted gateways. It can be configured several times under different names
+// by adding "provider": "openai-compatible" to a model's config.
+type compatibleClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+func (c *compatibleClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var resp openAIResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices from %s", c.baseURL)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (c *compatibleClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readChatStream(res.Body, onToken)
+}
+
+// post sends the chat completions request and returns the 2xx response.
+func (c *compatibleClient) post(ctx context.Context, prompt string, maxTokens int, stream bool) (*http.Response, error) {
+	body := openAIReq{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: "You are an assistant that writes Conventional Commit messages. Output only the commit message; no code fences."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokens,
+		Temperature: 0.2,
+		Stream:      stream,
+	}
+
+	b, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		return nil, compatibleError(res)
+	}
+	return res, nil
+}
+
+// models lists the model IDs the server reports at GET <base>/models.
+func (c *compatibleClient) models(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, compatibleError(res)
+	}
+	var resp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, m := range resp.Data {
+		if m.ID != "" {
+			ids = append(ids, m.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (c *compatibleClient) setHeaders(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// compatibleError names the server by host, since instances share a provider.
+func compatibleError(res *http.Response) error {
+	return ai.ResponseError(res.Request.URL.Host, res)
+}
+
+// compatibleBase normalizes a base URL: a bare host gets the usual /v1
+// prefix, while a URL with a path (e.g. https://api.groq.com/openai/v1) is
+// used as given.
+func compatibleBase(raw string) (string, error) {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid base_url %q; expected e.g. %s", raw, compatibleBaseURL)
+	}
+	if u.Path == "" {
+		raw += "/v1"
+	}
+	return raw, nil
+}
+
+func newCompatible(config map[string]string) (*compatibleClient, error) {
+	base := config["base_url"]
+	if strings.TrimSpace(base) == "" {
+		return nil, fmt.Errorf("missing base_url for an openai-compatible model; run 'gessage setup --model <name> --provider openai-compatible'")
+	}
+	base, err := compatibleBase(base)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for k, v := range config {
+		if name, ok := strings.CutPrefix(k, compatibleHeaderPrefix); ok && name != "" {
+			headers[name] = v
+		}
+	}
+	return &compatibleClient{
+		baseURL:    base,
+		apiKey:     strings.TrimSpace(config["api_key"]),
+		model:      strings.TrimSpace(config["model"]),
+		headers:    headers,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func newCompatibleFromConfig(config map[string]string) (ai.Client, error) {
+	c, err := newCompatible(config)
+	if err != nil {
+		return nil, err
+	}
+	if c.model == "" {
+		return nil, fmt.Errorf("missing model for %s; set one with 'gessage default --model <name>'", c.baseURL)
+	}
+	return c, nil
+}
+
+func discoverCompatible(ctx context.Context, config map[string]string) ([]string, error) {
+	c, err := newCompatible(config)
+	if err != nil {
+		return nil, err
+	}
+	return c.models(ctx)
+}
+
+// setupCompatible prompts for the base URL, an optional API key and extra
+// headers, then offers the models the server reports.
+func setupCompatible(ctx context.Context) (map[string]string, error) {
+	in := bufio.NewReader(os.Stdin)
+	ask := func(label string) string {
+		fmt.Print(color.HiWhiteString(label))
+		v, _ := in.ReadString('\n')
+		return strings.TrimSpace(v)
+	}
+
+	color.Cyan("OpenAI-compatible server setup")
+	color.Yellow("Works with vLLM, LM Studio, llama.cpp (llama-server), LocalAI and other servers exposing /v1/chat/completions.")
+	base := ask(fmt.Sprintf("Base URL [%s]: ", compatibleBaseURL))
+	if base == "" {
+		base = compatibleBaseURL
+	}
+	if _, err := compatibleBase(base); err != nil {
+		return nil, err
+	}
+	cfg := map[string]string{"base_url": base}
+	if key := ask("API key (leave empty if the server needs none): "); key != "" {
+		cfg["api_key"] = key
+	}
+	for {
+		h := ask("Extra header as 'Name: value' (leave empty to finish): ")
+		if h == "" {
+			break
+		}
+		name, value, ok := strings.Cut(h, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			color.Yellow("Expected 'Name: value'; skipped.")
+			continue
+		}
+		cfg[compatibleHeaderPrefix+name] = strings.TrimSpace(value)
+	}
+
+	models, err := discoverCompatible(ctx, cfg)
+	if err != nil {
+		color.Yellow("Could not list models: %v", err)
+	}
+	if len(models) > 0 {
+		idx, err := ui.Select("Select a default model:", models, 0)
+		if err != nil {
+			return nil, err
+		}
+		cfg["model"] = models[idx]
+		return cfg, nil
+	}
+	model := ask("Model name: ")
+	if model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	cfg["model"] = model
+	return cfg, nil
+}
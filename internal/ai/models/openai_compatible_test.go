@@ -0,0 +1,195 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+)
+
+func TestCompatibleBase(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		wantErr   bool
+	}{
+		{raw: "http://localhost:1234", want: "http://localhost:1234/v1"},
+		{raw: " http://localhost:8000/ ", want: "http://localhost:8000/v1"},
+		{raw: "http://localhost:1234/v1", want: "http://localhost:1234/v1"},
+		{raw: "https://api.groq.com/openai/v1/", want: "https://api.groq.com/openai/v1"},
+		{raw: "https://gateway.example.com/llm", want: "https://gateway.example.com/llm"},
+		{raw: "localhost:1234", wantErr: true},
+		{raw: "/v1", wantErr: true},
+		{raw: "http://", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := compatibleBase(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("compatibleBase(%q) = %q, %v, want %q (error %v)", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewCompatibleFromConfig(t *testing.T) {
+	for _, tt := range []struct {
+		config map[string]string
+		err    string
+	}{
+		{config: map[string]string{"model": "m"}, err: "missing base_url"},
+		{config: map[string]string{"base_url": "http://localhost:1234"}, err: "missing model for http://localhost:1234/v1"},
+		{config: map[string]string{"base_url": "ftp//x", "model": "m"}, err: "invalid base_url"},
+	} {
+		_, err := newCompatibleFromConfig(tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("newCompatibleFromConfig(%v) = %v, want %q", tt.config, err, tt.err)
+		}
+	}
+}
+
+// compatibleServer stands in for an OpenAI-compatible server under /v1 and
+// checks that every request carries the configured key and extra headers.
+func compatibleServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-local" {
+			t.Errorf("%s %s: Authorization = %q", r.Method, r.URL.Path, got)
+		}
+		if got := r.Header.Get("X-Team"); got != "platform" {
+			t.Errorf("%s %s: X-Team = %q", r.Method, r.URL.Path, got)
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func compatibleConfig(srv *httptest.Server) map[string]string {
+	return map[string]string{
+		"base_url":      srv.URL, // a bare host: /v1 is added
+		"api_key":       "sk-local",
+		"model":         "qwen2.5-coder-7b",
+		"header.X-Team": "platform",
+		"header.":       "ignored",
+	}
+}
+
+func TestCompatibleGenerate(t *testing.T) {
+	srv := compatibleServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		var req openAIReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "qwen2.5-coder-7b" || req.MaxTokens != 128 || req.Stream {
+			t.Errorf("model, max_tokens, stream = %q, %d, %v", req.Model, req.MaxTokens, req.Stream)
+		}
+		if len(req.Messages) != 2 || req.Messages[1].Content != "the prompt" {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"feat: add login"}}]}`)
+	})
+	c, err := newCompatibleFromConfig(compatibleConfig(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Generate(context.Background(), "the prompt", 128)
+	if err != nil || got != "feat: add login" {
+		t.Errorf("Generate() = %q, %v", got, err)
+	}
+}
+
+func TestCompatibleGenerateStream(t *testing.T) {
+	srv := compatibleServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openAIReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("stream request = %+v, %v", req, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"fix\"}}]}\n\n"+
+			"data: {\"choices\":[{\"delta\":{\"content\":\": typo\"}}]}\n\ndata: [DONE]\n\n")
+	})
+	c, err := newCompatibleFromConfig(compatibleConfig(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	got, err := c.(ai.StreamingClient).GenerateStream(context.Background(), "p", 64, func(tok string) { tokens = append(tokens, tok) })
+	if err != nil || got != "fix: typo" || !reflect.DeepEqual(tokens, []string{"fix", ": typo"}) {
+		t.Errorf("GenerateStream() = %q, %v (tokens %q)", got, err, tokens)
+	}
+}
+
+func TestDiscoverCompatible(t *testing.T) {
+	srv := compatibleServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/models" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[
+			{"id":"qwen2.5-coder-7b","object":"model"},
+			{"id":"","object":"model"},
+			{"id":"llama-3.1-8b","object":"model"}
+		]}`)
+	})
+	got, err := discoverCompatible(context.Background(), compatibleConfig(srv))
+	if want := []string{"llama-3.1-8b", "qwen2.5-coder-7b"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverCompatible() = %q, %v, want %q", got, err, want)
+	}
+
+	// The model is not needed to list them
+	cfg := compatibleConfig(srv)
+	delete(cfg, "model")
+	if _, err := discoverCompatible(context.Background(), cfg); err != nil {
+		t.Errorf("discoverCompatible() without a model = %v", err)
+	}
+}
+
+func TestCompatibleError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{name: "bad key", status: 401, body: `{"error":{"message":"Invalid API Key","type":"invalid_request_error","code":"invalid_api_key"}}`, kind: ai.ErrAuth},
+		{name: "unknown model", status: 404, body: `{"error":{"message":"The model 'nope' does not exist","type":"NotFoundError","code":404}}`, kind: ai.ErrModelNotFound},
+		{name: "context", status: 400, body: `{"object":"error","message":"x","type":"BadRequestError","code":400,"error":{"message":"This model's maximum context length is 4096 tokens."}}`, kind: ai.ErrContextLength},
+		{name: "plain text", status: 503, body: "llama-server is loading the model", kind: ai.ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := compatibleServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			c, err := newCompatibleFromConfig(compatibleConfig(srv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.Generate(context.Background(), "p", 64)
+			var pe *ai.ProviderError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Generate() = %v, want a *ai.ProviderError", err)
+			}
+			// Instances share the provider, so the server is named by host
+			if host := strings.TrimPrefix(srv.URL, "http://"); pe.Provider != host {
+				t.Errorf("Provider = %q, want %q", pe.Provider, host)
+			}
+			if pe.Status != tt.status || !errors.Is(err, tt.kind) {
+				t.Errorf("Generate() = %v, want status %d and %v", err, tt.status, tt.kind)
+			}
+			if _, err := discoverCompatible(context.Background(), compatibleConfig(srv)); !errors.Is(err, tt.kind) {
+				t.Errorf("discoverCompatible() = %v, want %v", err, tt.kind)
+			}
+		})
+	}
+}
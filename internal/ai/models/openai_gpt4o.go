@@ -24,7 +24,8 @@ func init() {
 // openaiClient implements ai.Client for OpenAI chat completions
 // using a minimal subset required by this app.
 //
-// It does not stream; it requests a single completion.
+// It requests a single completion, or streams it over server-sent events
+// through GenerateStream.
 // Endpoint and model are configurable via per-model config.
 
 type openaiClient struct {
@@ -38,6 +39,7 @@ type openAIReq struct {
 	Messages    []openAIMessage `json:"messages"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float32         `json:"temperature,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
 }
 
 type openAIMessage struct {
@@ -52,6 +54,33 @@ type openAIResp struct {
 }
 
 func (c *openaiClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var resp openAIResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices from openai")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (c *openaiClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readChatStream(res.Body, onToken)
+}
+
+// post sends the chat completions request and returns the 2xx response.
+func (c *openaiClient) post(ctx context.Context, prompt string, maxTokens int, stream bool) (*http.Response, error) {
 	body := openAIReq{
 		Model: c.model,
 		Messages: []openAIMessage{
@@ -60,12 +89,13 @@ func (c *openaiClient) Generate(ctx context.Context, prompt string, maxTokens in
 		},
 		MaxTokens:   maxTokens,
 		Temperature: 0.2,
+		Stream:      stream,
 	}
 
 	b, _ := json.Marshal(body)
 	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(b))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -73,21 +103,13 @@ func (c *openaiClient) Generate(ctx context.Context, prompt string, maxTokens in
 	httpClient := &http.Client{Timeout: 40 * time.Second}
 	res, err := httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", fmt.Errorf("openai error: status %s", res.Status)
-	}
-
-	var resp openAIResp
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return "", err
+		defer res.Body.Close()
+		return nil, ai.ResponseError("openai", res)
 	}
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no choices from openai")
-	}
-	return resp.Choices[0].Message.Content, nil
+	return res, nil
 }
 
 func newOpenAIFromConfig(config map[string]string) (ai.Client, error) {
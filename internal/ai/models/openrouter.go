@@ -48,6 +48,7 @@ type orReq struct {
 	Messages    []orMessage `json:"messages"`
 	MaxTokens   int         `json:"max_tokens,omitempty"`
 	Temperature float32     `json:"temperature,omitempty"`
+	Stream      bool        `json:"stream,omitempty"`
 }
 
 type orResp struct {
@@ -57,6 +58,33 @@ type orResp struct {
 }
 
 func (c *openRouterClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var resp orResp
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices from openrouter")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func (c *openRouterClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	res, err := c.post(ctx, prompt, maxTokens, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readChatStream(res.Body, onToken)
+}
+
+// post sends the chat completions request and returns the 2xx response.
+func (c *openRouterClient) post(ctx context.Context, prompt string, maxTokens int, stream bool) (*http.Response, error) {
 	body := orReq{
 		Model: c.model,
 		Messages: []orMessage{
@@ -65,33 +93,26 @@ func (c *openRouterClient) Generate(ctx context.Context, prompt string, maxToken
 		},
 		MaxTokens:   maxTokens,
 		Temperature: 0.2,
+		Stream:      stream,
 	}
 
 	b, _ := json.Marshal(body)
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewReader(b))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", fmt.Errorf("openrouter error: status %s", res.Status)
-	}
-
-	var resp orResp
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		return "", err
+		defer res.Body.Close()
+		return nil, ai.ResponseError("openrouter", res)
 	}
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no choices from openrouter")
-	}
-	return resp.Choices[0].Message.Content, nil
+	return res, nil
 }
 
 func newOpenRouterFromConfig(config map[string]string) (ai.Client, error) {
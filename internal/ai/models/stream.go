@@ -0,0 +1,86 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readChatStream reads an OpenAI-style chat completions stream: server-sent
+// events whose data lines carry chunks with a content delta, ended by
+// "data: [DONE]". Each delta is passed to onToken; the joined text is
+// returned. A body that ends without [DONE] still returns what arrived.
+func readChatStream(r io.Reader, onToken func(string)) (string, error) {
+	var out strings.Builder
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue // comments (": keep-alive"), event names and blank separators
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			if sc.Err() != nil {
+				// The connection dropped partway through this line
+				return out.String(), sc.Err()
+			}
+			return out.String(), fmt.Errorf("bad stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return out.String(), errors.New(chunk.Error.Message)
+		}
+		for _, ch := range chunk.Choices {
+			if ch.Delta.Content != "" {
+				out.WriteString(ch.Delta.Content)
+				onToken(ch.Delta.Content)
+			}
+		}
+	}
+	return out.String(), sc.Err()
+}
+
+// readOllamaStream reads Ollama's newline-delimited JSON stream from
+// /api/generate until a line reports done.
+func readOllamaStream(r io.Reader, onToken func(string)) (string, error) {
+	var out strings.Builder
+	dec := json.NewDecoder(r)
+	for {
+		var chunk struct {
+			ollamaResp
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return out.String(), nil
+			}
+			return out.String(), err
+		}
+		if chunk.Error != "" {
+			return out.String(), fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		if chunk.Response != "" {
+			out.WriteString(chunk.Response)
+			onToken(chunk.Response)
+		}
+		if chunk.Done {
+			return out.String(), nil
+		}
+	}
+}
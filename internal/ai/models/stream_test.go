@@ -0,0 +1,220 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// streamServer serves body line by line, flushing after each one. When
+// truncated, it promises more bytes than it sends so the connection drops
+// mid-stream.
+func streamServer(t *testing.T, contentType, body string, truncated bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if truncated {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)+100))
+		}
+		for _, line := range strings.SplitAfter(body, "\n") {
+			io.WriteString(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// getStream fetches a stream from srv for one of the read*Stream functions.
+func getStream(t *testing.T, srv *httptest.Server) io.ReadCloser {
+	t.Helper()
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res.Body
+}
+
+func TestReadChatStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		truncated bool
+		tokens    []string
+		err       string
+	}{
+		{
+			name: "done",
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"feat: \"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"add login\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+				"data: [DONE]\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"ignored\"}}]}\n\n",
+			tokens: []string{"feat: ", "add login"},
+		},
+		{
+			name: "keep-alive comments and events",
+			body: ": OPENROUTER PROCESSING\n\n" +
+				"event: message\n" +
+				"data:{\"choices\":[{\"delta\":{\"content\":\"fix\"}}]}\n\n" +
+				": keep-alive\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\": typo\"}}]}\r\n\r\n" +
+				"data: [DONE]\n",
+			tokens: []string{"fix", ": typo"},
+		},
+		{
+			name: "error chunk",
+			body: "data: {\"choices\":[{\"delta\":{\"content\":\"feat: \"}}]}\n\n" +
+				"data: {\"error\":{\"message\":\"upstream overloaded\",\"code\":502}}\n\n" +
+				"data: [DONE]\n\n",
+			tokens: []string{"feat: "},
+			err:    "upstream overloaded",
+		},
+		{
+			name:   "bad chunk",
+			body:   "data: {\"choices\":[{\"delta\":{\"content\":\"feat\"}}]}\n\ndata: {not json\n\n",
+			tokens: []string{"feat"},
+			err:    "bad stream chunk",
+		},
+		{
+			name:   "ends without done",
+			body:   "data: {\"choices\":[{\"delta\":{\"content\":\"docs: readme\"}}]}\n\n",
+			tokens: []string{"docs: readme"},
+		},
+		{
+			name:      "truncated",
+			body:      "data: {\"choices\":[{\"delta\":{\"content\":\"feat: \"}}]}\n\ndata: {\"choices\":[{\"del",
+			truncated: true,
+			tokens:    []string{"feat: "},
+			err:       io.ErrUnexpectedEOF.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := getStream(t, streamServer(t, "text/event-stream", tt.body, tt.truncated))
+			var tokens []string
+			out, err := readChatStream(body, func(tok string) { tokens = append(tokens, tok) })
+			if !reflect.DeepEqual(tokens, tt.tokens) {
+				t.Errorf("tokens = %q, want %q", tokens, tt.tokens)
+			}
+			if want := strings.Join(tt.tokens, ""); out != want {
+				t.Errorf("readChatStream() = %q, want %q", out, want)
+			}
+			if got := errString(err); !strings.Contains(got, tt.err) || (tt.err == "") != (err == nil) {
+				t.Errorf("readChatStream() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReadOllamaStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		truncated bool
+		tokens    []string
+		err       string
+	}{
+		{
+			name: "done",
+			body: `{"model":"qwen2.5-coder:3b","response":"feat","done":false}` + "\n" +
+				`{"model":"qwen2.5-coder:3b","response":": add login","done":false}` + "\n" +
+				`{"model":"qwen2.5-coder:3b","response":"","done":true,"done_reason":"stop","eval_count":9}` + "\n" +
+				`{"response":"ignored","done":false}` + "\n",
+			tokens: []string{"feat", ": add login"},
+		},
+		{
+			name:   "final token on the done line",
+			body:   `{"response":"fix: ","done":false}` + "\n" + `{"response":"typo","done":true}` + "\n",
+			tokens: []string{"fix: ", "typo"},
+		},
+		{
+			name:   "error line",
+			body:   `{"response":"feat","done":false}` + "\n" + `{"error":"llama runner process has terminated"}` + "\n",
+			tokens: []string{"feat"},
+			err:    "ollama error: llama runner process has terminated",
+		},
+		{
+			name:   "ends without done",
+			body:   `{"response":"chore: bump","done":false}` + "\n",
+			tokens: []string{"chore: bump"},
+		},
+		{
+			name:      "truncated",
+			body:      `{"response":"feat","done":false}` + "\n" + `{"respon`,
+			truncated: true,
+			tokens:    []string{"feat"},
+			err:       "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := getStream(t, streamServer(t, "application/x-ndjson", tt.body, tt.truncated))
+			var tokens []string
+			out, err := readOllamaStream(body, func(tok string) { tokens = append(tokens, tok) })
+			if !reflect.DeepEqual(tokens, tt.tokens) {
+				t.Errorf("tokens = %q, want %q", tokens, tt.tokens)
+			}
+			if want := strings.Join(tt.tokens, ""); out != want {
+				t.Errorf("readOllamaStream() = %q, want %q", out, want)
+			}
+			if got := errString(err); !strings.Contains(got, tt.err) || (tt.err == "") != (err == nil) {
+				t.Errorf("readOllamaStream() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOllamaGenerateStream(t *testing.T) {
+	srv := streamServer(t, "application/x-ndjson",
+		`{"response":"feat","done":false}`+"\n"+`{"response":": stream","done":true}`+"\n", false)
+	c, err := newOllamaFromConfig(map[string]string{"host": srv.URL, "model": "qwen2.5-coder:3b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got strings.Builder
+	out, err := c.(*ollamaClient).GenerateStream(context.Background(), "p", 64, func(tok string) { got.WriteString(tok) })
+	if err != nil || out != "feat: stream" || got.String() != out {
+		t.Errorf("GenerateStream() = %q, %v (tokens %q)", out, err, got.String())
+	}
+}
+
+func TestStreamCancelled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"feat\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, err := newCompatibleFromConfig(map[string]string{"base_url": srv.URL, "model": "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out, err := c.(*compatibleClient).GenerateStream(ctx, "p", 64, func(string) { cancel() })
+	if out != "feat" || !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateStream() = %q, %v, want the first token and context.Canceled", out, err)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
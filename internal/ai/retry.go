@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy repeats requests that failed with a temporary *ProviderError
+// (rate limits and server errors), waiting with jittered exponential backoff
+// or as long as the provider's Retry-After asks.
+type RetryPolicy struct {
+	Attempts int           // total tries, including the first
+	Base     time.Duration // backoff before the second try; doubles after each
+	Max      time.Duration // longest wait; a longer Retry-After is not waited for
+}
+
+// DefaultRetry is the policy Create applies to every client.
+var DefaultRetry = RetryPolicy{Attempts: 3, Base: time.Second, Max: 20 * time.Second}
+
+// Do calls fn until it succeeds, fails permanently, runs out of attempts or
+// ctx is done. It returns fn's last error.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.Attempts {
+			return err
+		}
+		wait, ok := p.backoff(attempt, err)
+		if !ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns the wait before retrying after the given attempt, and
+// false when err is not worth retrying.
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var pe *ProviderError
+	if !errors.As(err, &pe) || !pe.Temporary() {
+		return 0, false
+	}
+	if pe.RetryAfter > 0 {
+		return pe.RetryAfter, pe.RetryAfter <= p.Max
+	}
+	d := p.Base << (attempt - 1)
+	if d <= 0 || d > p.Max {
+		d = p.Max
+	}
+	// Full jitter in [d/2, d) keeps parallel clients from retrying in step.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// retryClient applies a RetryPolicy to Generate.
+type retryClient struct {
+	Client
+	policy RetryPolicy
+}
+
+func (c retryClient) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	var out string
+	err := c.policy.Do(ctx, func() error {
+		var err error
+		out, err = c.Client.Generate(ctx, prompt, maxTokens)
+		return err
+	})
+	return out, err
+}
+
+// retryStreamingClient also retries GenerateStream, but only while no token
+// has been delivered; a stream that broke halfway is not replayed.
+type retryStreamingClient struct {
+	retryClient
+	stream StreamingClient
+}
+
+func (c retryStreamingClient) GenerateStream(ctx context.Context, prompt string, maxTokens int, onToken func(string)) (string, error) {
+	var out string
+	started := false
+	err := c.policy.Do(ctx, func() error {
+		var err error
+		out, err = c.stream.GenerateStream(ctx, prompt, maxTokens, func(token string) {
+			started = true
+			onToken(token)
+		})
+		if err != nil && started {
+			return streamStarted{err}
+		}
+		return err
+	})
+	if s, ok := err.(streamStarted); ok {
+		err = s.err
+	}
+	return out, err
+}
+
+// streamStarted hides an error from the retry policy once part of the
+// stream was shown.
+type streamStarted struct{ err error }
+
+func (s streamStarted) Error() string { return s.err.Error() }
+
+// WithRetry wraps c so its requests follow policy, keeping StreamingClient
+// when c implements it.
+func WithRetry(c Client, policy RetryPolicy) Client {
+	rc := retryClient{Client: c, policy: policy}
+	if sc, ok := c.(StreamingClient); ok {
+		return retryStreamingClient{retryClient: rc, stream: sc}
+	}
+	return rc
+}
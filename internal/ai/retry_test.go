@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{Attempts: 10, Base: 100 * time.Millisecond, Max: time.Second}
+	temporary := &ProviderError{Status: 503, Kind: ErrServer}
+	for attempt, ceiling := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+		// a shift past the width of Duration still waits at most Max
+		70: time.Second,
+	} {
+		for range 200 {
+			wait, ok := p.backoff(attempt, temporary)
+			if !ok {
+				t.Fatalf("backoff(%d) gave up on a temporary error", attempt)
+			}
+			if wait < ceiling/2 || wait > ceiling {
+				t.Fatalf("backoff(%d) = %v, want %v..%v", attempt, wait, ceiling/2, ceiling)
+			}
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, Base: 100 * time.Millisecond, Max: 5 * time.Second}
+	tests := []struct {
+		name string
+		err  error
+		wait time.Duration
+		ok   bool
+	}{
+		{name: "honoured", err: &ProviderError{Status: 429, Kind: ErrRateLimited, RetryAfter: 3 * time.Second}, wait: 3 * time.Second, ok: true},
+		{name: "at max", err: &ProviderError{Status: 429, Kind: ErrRateLimited, RetryAfter: 5 * time.Second}, wait: 5 * time.Second, ok: true},
+		{name: "beyond max", err: &ProviderError{Status: 429, Kind: ErrRateLimited, RetryAfter: time.Minute}, wait: time.Minute},
+		{name: "quota", err: &ProviderError{Status: 429, Kind: ErrRateLimited, Code: "insufficient_quota", RetryAfter: time.Second}},
+		{name: "auth", err: &ProviderError{Status: 401, Kind: ErrAuth}},
+		{name: "context length", err: &ProviderError{Status: 400, Kind: ErrContextLength}},
+		{name: "unclassified", err: &ProviderError{Status: 400}},
+		{name: "not a provider error", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := p.backoff(1, tt.err)
+			if wait != tt.wait || ok != tt.ok {
+				t.Errorf("backoff(1, %v) = %v, %v, want %v, %v", tt.err, wait, ok, tt.wait, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryDo(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, Base: time.Millisecond, Max: 10 * time.Millisecond}
+	rateLimited := &ProviderError{Status: 429, Kind: ErrRateLimited}
+	tests := []struct {
+		name  string
+		errs  []error // returned by successive calls; nil after the last
+		calls int
+		err   error
+	}{
+		{name: "success", calls: 1},
+		{name: "recovers", errs: []error{rateLimited, &ProviderError{Status: 502, Kind: ErrServer}}, calls: 3},
+		{name: "gives up after attempts", errs: []error{rateLimited, rateLimited, rateLimited, rateLimited}, calls: 3, err: rateLimited},
+		{
+			name:  "retry-after beyond max",
+			errs:  []error{&ProviderError{Status: 429, Kind: ErrRateLimited, RetryAfter: time.Hour}},
+			calls: 1,
+			err:   ErrRateLimited,
+		},
+		{name: "permanent", errs: []error{&ProviderError{Status: 401, Kind: ErrAuth}}, calls: 1, err: ErrAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := p.Do(context.Background(), func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.calls {
+				t.Errorf("Do() called fn %d times, want %d", calls, tt.calls)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Do() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetryDoCancelled(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, Base: time.Hour, Max: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- p.Do(ctx, func() error {
+			calls++
+			return &ProviderError{Status: 503, Kind: ErrServer}
+		})
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServer) || calls != 1 {
+			t.Errorf("Do() = %v after %d call(s), want ErrServer after 1", err, calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do() kept waiting after the context was cancelled")
+	}
+}
+
+// flakyStream fails with errs in turn, sending tokens first when asked to.
+type flakyStream struct {
+	errs   []error
+	tokens []string
+	calls  int
+}
+
+func (s *flakyStream) Generate(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	return s.GenerateStream(ctx, prompt, maxTokens, func(string) {})
+}
+
+func (s *flakyStream) GenerateStream(_ context.Context, _ string, _ int, onToken func(string)) (string, error) {
+	s.calls++
+	out := ""
+	for _, tok := range s.tokens {
+		onToken(tok)
+		out += tok
+	}
+	if s.calls <= len(s.errs) {
+		return out, s.errs[s.calls-1]
+	}
+	return out, nil
+}
+
+func TestWithRetryStream(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, Base: time.Millisecond, Max: 10 * time.Millisecond}
+	overloaded := &ProviderError{Status: 529, Kind: ErrServer}
+
+	// Nothing shown yet: the request is repeated
+	s := &flakyStream{errs: []error{overloaded}}
+	c, ok := WithRetry(s, p).(StreamingClient)
+	if !ok {
+		t.Fatal("WithRetry dropped StreamingClient")
+	}
+	if _, err := c.GenerateStream(context.Background(), "p", 1, func(string) {}); err != nil || s.calls != 2 {
+		t.Errorf("GenerateStream() = %v after %d call(s), want success after 2", err, s.calls)
+	}
+
+	// Tokens already shown: the error is returned as is, not replayed
+	s = &flakyStream{errs: []error{overloaded}, tokens: []string{"feat: "}}
+	c = WithRetry(s, p).(StreamingClient)
+	out, err := c.GenerateStream(context.Background(), "p", 1, func(string) {})
+	if !errors.Is(err, ErrServer) || s.calls != 1 || out != "feat: " {
+		t.Errorf("GenerateStream() = %q, %v after %d call(s), want the partial text and ErrServer after 1", out, err, s.calls)
+	}
+	var pe *ProviderError
+	if !errors.As(err, &pe) {
+		t.Errorf("GenerateStream() error %T does not unwrap to *ProviderError", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package changelog renders Keep a Changelog sections from Conventional
+// Commit history. Output depends only on its inputs so it is reproducible.
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// Header starts a new CHANGELOG.md file.
+const Header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// sectionTitles maps each allowed commit type to its changelog heading.
+var sectionTitles = map[string]string{
+	"feat":     "Features",
+	"fix":      "Bug Fixes",
+	"perf":     "Performance",
+	"refactor": "Refactoring",
+	"docs":     "Documentation",
+	"style":    "Styles",
+	"test":     "Tests",
+	"chore":    "Chores",
+}
+
+type Options struct {
+	Version string // e.g. "1.4.0" or "Unreleased"
+	Date    string // YYYY-MM-DD; omitted when empty
+	RepoURL string // web URL used to link commits; hashes are plain when empty
+}
+
+// Render builds a changelog section for commits (newest first, as git log
+// prints them). Commits that are not Conventional Commits are skipped.
+func Render(commits []git.Commit, opt Options) string {
+	type entry struct {
+		commit git.Commit
+		cc     format.ConventionalCommit
+	}
+	byType := map[string][]entry{}
+	var breaking []entry
+	for _, c := range commits {
+		cc, ok := format.ParseConventional(c.Message)
+		if !ok {
+			continue
+		}
+		if cc.Breaking {
+			breaking = append(breaking, entry{c, cc})
+		}
+		byType[cc.Type] = append(byType[cc.Type], entry{c, cc})
+	}
+
+	var b strings.Builder
+	b.WriteString(heading(opt))
+	b.WriteString("\n")
+	if len(breaking) > 0 {
+		b.WriteString("\n### BREAKING CHANGES\n\n")
+		for _, e := range breaking {
+			for _, note := range e.cc.BreakingNotes() {
+				b.WriteString("- " + scopePrefix(e.cc) + indentContinuation(note) + " " + link(e.commit.Hash, opt.RepoURL) + "\n")
+			}
+		}
+	}
+	for _, t := range format.AllowedTypes {
+		entries := byType[t]
+		if len(entries) == 0 {
+			continue
+		}
+		b.WriteString("\n### " + sectionTitles[t] + "\n\n")
+		for _, e := range entries {
+			b.WriteString("- " + scopePrefix(e.cc) + e.cc.Description + " " + link(e.commit.Hash, opt.RepoURL) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Insert places section into an existing changelog. A section with the same
+// heading is replaced; otherwise the section goes above the newest release
+// (below any Unreleased section). An empty changelog gets the standard header.
+func Insert(existing, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return Header + "\n" + section
+	}
+	lines := strings.Split(existing, "\n")
+	newHeading := strings.SplitN(section, "\n", 2)[0]
+
+	// Replace a section with the same version
+	for i, ln := range lines {
+		if sameVersion(ln, newHeading) {
+			end := nextHeading(lines, i+1)
+			out := append([]string{}, lines[:i]...)
+			out = append(out, strings.Split(strings.TrimRight(section, "\n"), "\n")...)
+			if end < len(lines) {
+				out = append(out, "")
+			}
+			out = append(out, lines[end:]...)
+			return strings.Join(out, "\n")
+		}
+	}
+
+	at := nextHeading(lines, 0)
+	if at < len(lines) && isUnreleased(lines[at]) {
+		at = nextHeading(lines, at+1)
+	}
+	if at == len(lines) {
+		return strings.TrimRight(existing, "\n") + "\n\n" + section
+	}
+	out := append([]string{}, lines[:at]...)
+	out = append(out, strings.Split(strings.TrimRight(section, "\n"), "\n")...)
+	out = append(out, "")
+	out = append(out, lines[at:]...)
+	return strings.Join(out, "\n")
+}
+
+var versionRe = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+
+func heading(opt Options) string {
+	version := opt.Version
+	if version == "" {
+		version = "Unreleased"
+	}
+	h := "## [" + version + "]"
+	if opt.Date != "" && !strings.EqualFold(version, "Unreleased") {
+		h += " - " + opt.Date
+	}
+	return h
+}
+
+func sameVersion(line, heading string) bool {
+	a := versionRe.FindStringSubmatch(line)
+	b := versionRe.FindStringSubmatch(heading)
+	return a != nil && b != nil && strings.EqualFold(a[1], b[1])
+}
+
+func isUnreleased(line string) bool {
+	m := versionRe.FindStringSubmatch(line)
+	return m != nil && strings.EqualFold(m[1], "Unreleased")
+}
+
+// nextHeading returns the index of the first release heading at or after from.
+func nextHeading(lines []string, from int) int {
+	for i := from; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func scopePrefix(cc format.ConventionalCommit) string {
+	if cc.Scope == "" {
+		return ""
+	}
+	return "**" + cc.Scope + ":** "
+}
+
+// indentContinuation keeps multi-line notes inside their list item.
+func indentContinuation(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ")
+}
+
+func link(hash, repoURL string) string {
+	short := hash
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	if repoURL == "" {
+		return "(`" + short + "`)"
+	}
+	return "([" + short + "](" + strings.TrimRight(repoURL, "/") + "/commit/" + hash + "))"
+}
@@ -0,0 +1,81 @@
+package changelog
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden with the current output")
+
+// history is newest first, as git log prints it.
+var history = []git.Commit{
+	{Hash: "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678", Message: "feat(api)!: drop the v1 endpoints"},
+	{Hash: "b2c3d4e5f60718293a4b5c6d7e8f901234567890", Message: "fix(auth): refresh expired tokens\n\nTokens were reused after expiry.\n\nBREAKING CHANGE: sessions now last 1 hour\n  instead of 24.\nRefs: PAY-482"},
+	{Hash: "c3d4e5f6", Message: "feat: export reports as CSV"},
+	{Hash: "d4e5f60718293a4b", Message: "Merge branch 'main' into feature"},
+	{Hash: "e5f60718293a4b5c", Message: "docs(readme): document the changelog command"},
+	{Hash: "f60718293a4b5c6d", Message: "perf: cache parsed templates"},
+}
+
+const released = Header + `
+## [1.1.0] - 2026-01-10
+
+### Bug Fixes
+
+- handle empty diffs (` + "`0123456`" + `)
+
+## [1.0.0] - 2025-12-01
+
+### Features
+
+- first release (` + "`89abcde`" + `)
+`
+
+const withUnreleased = Header + `
+## [Unreleased]
+
+- work in progress
+
+## [1.1.0] - 2026-01-10
+
+### Bug Fixes
+
+- handle empty diffs (` + "`0123456`" + `)
+`
+
+func TestGolden(t *testing.T) {
+	section := Render(history, Options{Version: "1.2.0", Date: "2026-02-01"})
+	tests := []struct {
+		name string
+		got  string
+	}{
+		{"render_linked", Render(history, Options{Version: "1.2.0", Date: "2026-02-01", RepoURL: "https://github.com/acme/app/"})},
+		{"render_unlinked", section},
+		{"render_unreleased", Render(history[2:], Options{Date: "2026-02-01"})},
+		{"insert_empty", Insert("", section)},
+		{"insert_above_latest", Insert(released, section)},
+		{"insert_below_unreleased", Insert(withUnreleased, section)},
+		{"insert_same_version", Insert(Insert(released, section), Render(history[:1], Options{Version: "1.2.0", Date: "2026-02-02"}))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(tt.got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.got != string(want) {
+				t.Errorf("output differs from %s (run go test -update to rewrite it)\ngot:\n%s\nwant:\n%s", path, tt.got, want)
+			}
+		})
+	}
+}
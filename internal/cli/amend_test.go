@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAmend(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	commitFile(t, "b.txt", "b\n", "wip")
+	parent := gitT(t, "rev-parse", "HEAD~1")
+	writeFile(t, "c.txt", "c\n")
+	gitT(t, "add", "c.txt")
+
+	out, err := runGessage(t, "a\n", []string{"feat: add b and c"}, "--model", "fake", "--amend")
+	if err != nil {
+		t.Fatalf("gessage --amend: %v\n%s", err, out)
+	}
+	if got := gitT(t, "log", "-1", "--format=%B"); got != "feat: add b and c" {
+		t.Errorf("HEAD message = %q, want the generated one", got)
+	}
+	if got := gitT(t, "rev-parse", "HEAD~1"); got != parent {
+		t.Errorf("HEAD~1 = %s, want %s: amending added a commit", got, parent)
+	}
+	if got := gitT(t, "show", "--name-only", "--format=", "HEAD"); got != "b.txt\nc.txt" {
+		t.Errorf("amended commit changes %q, want b.txt and c.txt", got)
+	}
+}
+
+func TestAmendPromptRedactsPreviousMessage(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "fix: set api_key=abcd1234abcd1234")
+	writeFile(t, "b.txt", "b\n")
+	gitT(t, "add", "b.txt")
+	useFakeModel(t, "fix: set the key")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"--model", "fake", "--amend", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, prompt, _ := strings.Cut(out, "=== [PROMPT] ===")
+	if strings.Contains(prompt, "abcd1234abcd1234") {
+		t.Errorf("amend prompt leaks a secret from HEAD's message:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "fix: set [REDACTED]") {
+		t.Errorf("amend prompt does not include HEAD's redacted message:\n%s", prompt)
+	}
+}
@@ -13,9 +13,9 @@ import (
 
 	"github.com/ispooya/gessage-cli/internal/ai"
 	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/diff"
 	"github.com/ispooya/gessage-cli/internal/format"
 	"github.com/ispooya/gessage-cli/internal/git"
-	"github.com/ispooya/gessage-cli/internal/sanitize"
 	"github.com/ispooya/gessage-cli/internal/ui"
 )
 
@@ -27,8 +27,26 @@ func NewApp() *App { return &App{} }
 // Run parses flags, wires dependencies, and executes the main flow.
 // Extend CLI here safely: add subcommands or extra flags without touching deeper layers.
 func (a *App) Run(ctx context.Context, argv []string) error {
-	// Print version early if requested
+	// Global -C <dir>: run as if started in dir, like `git -C`. It may be
+	// repeated, each one relative to the previous.
+	for len(argv) > 0 && argv[0] == "-C" {
+		if len(argv) < 2 {
+			return errors.New("-C requires a directory")
+		}
+		if argv[1] != "" {
+			if err := os.Chdir(argv[1]); err != nil {
+				return fmt.Errorf("cannot change to %s: %w", argv[1], err)
+			}
+		}
+		argv = argv[2:]
+	}
+
+	// Print version early if requested (subcommands may define their own --version)
+	isSubcommand := len(argv) > 0 && !strings.HasPrefix(argv[0], "-")
 	for _, arg := range argv {
+		if isSubcommand {
+			break
+		}
 		if arg == "--version" || arg == "-v" {
 			fmt.Println("gessage CLI", Version)
 			return nil
@@ -47,6 +65,46 @@ func (a *App) Run(ctx context.Context, argv []string) error {
 			printDefaultUsage()
 			return nil
 		}
+		if len(argv) > 1 && argv[1] == "hook" {
+			printHookUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "reword" {
+			printRewordUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "pr" {
+			printPRUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "changelog" {
+			printChangelogUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "release" {
+			printReleaseUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "split" {
+			printSplitUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "squash" {
+			printSquashUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "provenance" {
+			printProvenanceUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "style" {
+			printStyleUsage()
+			return nil
+		}
+		if len(argv) > 1 && argv[1] == "lint" {
+			printLintUsage()
+			return nil
+		}
 		printRootUsage()
 		return nil
 	}
@@ -59,76 +117,264 @@ func (a *App) Run(ctx context.Context, argv []string) error {
 	if len(argv) > 0 && argv[0] == "default" {
 		return a.runDefault(ctx, argv[1:])
 	}
+	if len(argv) > 0 && argv[0] == "lint" {
+		return a.runLint(ctx, argv[1:]) // files and stdin need no repository
+	}
 
-	// Flags for the root command `gessage`
+	// Everything below works on a repository; fail early with a clear message
+	// instead of a raw git error halfway through
+	if !wantsHelp(argv) && !readsPatch(argv) {
+		if _, err := git.Discover(ctx); err != nil {
+			return err
+		}
+	}
+	if len(argv) > 0 && argv[0] == "hook" {
+		return a.runHook(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "reword" {
+		return a.runReword(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "pr" {
+		return a.runPR(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "changelog" {
+		return a.runChangelog(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "release" {
+		return a.runRelease(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "split" {
+		return a.runSplit(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "squash" {
+		return a.runSquash(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "provenance" {
+		return a.runProvenance(ctx, argv[1:])
+	}
+	if len(argv) > 0 && argv[0] == "style" {
+		return a.runStyle(ctx, argv[1:])
+	}
+
+	// Flags for the root command `gessage`; positional arguments are pathspecs
+	// and anything after `--` goes to `git commit` unchanged
+	argv, passthrough := splitPassthrough(argv)
 	fs := flag.NewFlagSet("gessage", flag.ContinueOnError)
 	fs.Usage = printRootUsage
 
 	var (
-		flagModel     = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
-		flagAuto      = fs.Bool("auto", true, "Auto-select model based on diff size (overrides --model if needed)")
-		flagType      = fs.String("type", "", "Conventional commit type override (feat, fix, refactor, docs, chore, style, test, perf)")
-		flagNoCommit  = fs.Bool("no-commit", false, "Do not run `git commit`; just print the message")
-		flagMaxTokens = fs.Int("max-tokens", 512, "Max tokens for AI generation")
-		flagDryRun    = fs.Bool("dry-run", false, "Print sanitized diff and prompt; do not call AI")
-		flagMaxBytes  = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI (after sanitization)")
+		flagModel      = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
+		flagAuto       = fs.Bool("auto", true, "Auto-select model based on diff size (overrides --model if needed)")
+		flagType       = fs.String("type", "", "Conventional commit type override (feat, fix, refactor, docs, chore, style, test, perf)")
+		flagNoCommit   = fs.Bool("no-commit", false, "Do not run `git commit`; just print the message")
+		flagMaxTokens  = fs.Int("max-tokens", 512, "Max tokens for AI generation")
+		flagDryRun     = fs.Bool("dry-run", false, "Print sanitized diff and prompt; do not call AI")
+		flagMaxBytes   = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI (after sanitization)")
+		flagAmend      = fs.Bool("amend", false, "Regenerate the HEAD commit message from HEAD's diff plus staged changes, then amend")
+		flagNoVerify   = fs.Bool("no-verify", false, "Bypass pre-commit and commit-msg hooks")
+		flagAuthor     = fs.String("author", "", "Override the commit author (\"Name <email>\")")
+		flagAll        = fs.Bool("all", false, "Stage modified and deleted tracked files first, like `git commit -a`")
+		flagUntracked  = fs.Bool("include-untracked", false, "Also stage untracked files (respects .gitignore)")
+		flagProvenance = fs.Bool("provenance", false, "Record how the message was generated in a git note ("+git.NotesRef+")")
+		flagDiff       = fs.String("diff", "", "Describe the patch read from stdin (\"-\") instead of the index; implies --no-commit")
+		flagDiffFile   = fs.String("diff-file", "", "Describe the patch in a file instead of the index; implies --no-commit")
+		flagRange      = fs.String("range", "", "Describe a revision range (A..B) or a single commit instead of the index; implies --no-commit")
+		flagContext    = fs.String("context", "", "Code around each hunk: \"function\" or a number of lines (default from config, else 3)")
+		flagSignOff    bool
+		flagGPGSign    bool
+		flagTrailers   stringList
+		flagPairs      stringList
 	)
+	fs.BoolVar(&flagSignOff, "s", false, "Add a Signed-off-by trailer (DCO)")
+	fs.BoolVar(&flagSignOff, "signoff", false, "Add a Signed-off-by trailer (DCO)")
+	fs.BoolVar(&flagGPGSign, "S", false, "GPG-sign the commit")
+	fs.BoolVar(&flagGPGSign, "gpg-sign", false, "GPG-sign the commit")
+	fs.Var(&flagTrailers, "trailer", "Add a trailer key=value (repeatable)")
+	fs.Var(&flagPairs, "pair", "Add a Co-authored-by trailer for a pair alias from the config (repeatable)")
 	if err := fs.Parse(argv); err != nil {
 		if err == flag.ErrHelp {
 			return nil
 		}
 		return err
 	}
+	paths := fs.Args()
+	if err := checkPassthrough(passthrough); err != nil {
+		return err
+	}
+
+	// Step 1: Detect an in-progress merge, cherry-pick, revert, squash or rebase, then get
+	// the staged diff (or HEAD's diff plus staged changes when amending). A
+	// patch or range from the command line replaces the index and is never committed.
+	source := diffInput{Stdin: *flagDiff, File: *flagDiffFile, Range: *flagRange}
+	external := source.set()
+	if external {
+		if *flagAmend || *flagAll || *flagUntracked || len(paths) > 0 {
+			return errors.New("--diff, --diff-file and --range cannot be combined with --amend, --all, --include-untracked or pathspecs")
+		}
+		*flagNoCommit = true
+	}
+	var (
+		state git.State
+		err   error
+	)
+	if !external {
+		if state, err = git.DetectState(ctx); err != nil {
+			return err
+		}
+	}
+	if state.Op != git.OpNone {
+		if *flagAmend {
+			// Amending while a rebase is stopped (e.g. at "edit") is how commits
+			// are rewritten there; the others would conclude into HEAD instead.
+			switch state.Op {
+			case git.OpMerge, git.OpCherryPick, git.OpRevert:
+				return fmt.Errorf("cannot --amend while a %s is in progress", state.Op)
+			}
+			state.PreparedMessage = "" // the message being amended is HEAD's
+		}
+		if state.Op == git.OpMerge && len(paths) > 0 {
+			return errors.New("cannot commit only some paths while a merge is in progress")
+		}
+		color.Cyan("Detected %s in progress", state.Op)
+		if state.Op == git.OpRebase {
+			color.Yellow("Committing here does not continue the rebase; run 'git rebase --continue' afterwards.")
+		}
+	}
+	if *flagAmend && len(paths) > 0 {
+		return errors.New("--amend cannot be limited to paths")
+	}
 
-	// Step 1: Get staged diff
-	diff, err := git.GetStagedDiff(ctx)
+	// Stage what --all, --include-untracked and pathspecs ask for. The index is
+	// restored unless the commit is made, so cancelling leaves it untouched.
+	restoreIndex, err := stageWorkingTree(ctx, stageOptions{
+		All:              *flagAll,
+		IncludeUntracked: *flagUntracked,
+		Paths:            paths,
+	})
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(diff) == "" {
-		return errors.New("no staged changes. Use `git add` first")
-	}
+	committed := false
+	defer func() {
+		if !committed {
+			restoreIndex()
+		}
+	}()
 
-	// Step 2: Sanitize secrets before we ever hand this to an AI provider
-	safe, _ := sanitize.Redact(diff)
-	if len(safe) > *flagMaxBytes {
-		safe = safe[:*flagMaxBytes] + "\n... [TRUNCATED]\n"
+	var changes diff.Diff
+	var previous string
+	switch {
+	case external:
+		if changes, err = source.read(ctx); err != nil {
+			return err
+		}
+	case *flagAmend:
+		if changes, err = git.GetAmendDiff(ctx); err != nil {
+			return err
+		}
+		if previous, err = git.HeadMessage(ctx); err != nil {
+			return err
+		}
+	default:
+		if changes, err = git.GetStagedDiff(ctx, paths...); err != nil {
+			return err
+		}
+	}
+	if changes.Empty() && state.Op != git.OpMerge {
+		if external {
+			return fmt.Errorf("%s contains no changes", source)
+		}
+		if *flagAmend {
+			return errors.New("HEAD and the index contain no changes to describe")
+		}
+		if len(paths) > 0 {
+			return fmt.Errorf("no changes to commit in %s", strings.Join(paths, " "))
+		}
+		if *flagAll || *flagUntracked {
+			return errors.New("no changes to commit")
+		}
+		return errors.New("no staged changes. Use `git add` first")
 	}
 
-	// Step 3: Load persisted config
+	// Step 2: Load persisted config
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	// Step 4: Choose model strategy (user choice or auto)
-	modelName := *flagModel
-	if modelName == "" {
-		modelName = cfg.SelectedModel
+	// Step 3: Widen the context if asked, then sanitize secrets before we ever
+	// hand this to an AI provider. Tiny changes also get their files in full.
+	contextValue := *flagContext
+	if contextValue == "" {
+		contextValue = cfg.Context
+	}
+	contextOpts, err := parseContext(contextValue)
+	if err != nil {
+		return err
 	}
-	if *flagAuto {
-		modelName = ai.AutoSelectModelName(modelName, len(safe)) // selector may override based on diff size
+	safe, redactions := prepareDiff(ctx, widenContext(ctx, changes, contextOpts, *flagMaxBytes), *flagMaxBytes)
+	var fullFiles []format.FileContent
+	if !contextOpts.IsZero() {
+		fullFiles = postImages(ctx, changes, *flagMaxBytes-len(safe.String()))
 	}
-	if modelName == "" {
+	extraTrailers, err := commitTrailers(cfg, flagTrailers, flagPairs)
+	if err != nil {
+		return err
+	}
+	commitOpts := git.CommitOptions{
+		SignOff:  flagSignOff,
+		GPGSign:  flagGPGSign,
+		NoVerify: *flagNoVerify,
+		Author:   *flagAuthor,
+		Extra:    passthrough,
+		Paths:    paths,
+	}
+
+	// Step 4: Choose model strategy (user choice or auto)
+	modelName, err := resolveModelName(cfg, *flagModel, *flagAuto, len(safe.String()))
+	if err != nil {
 		color.Yellow("No model configured. Run: gessage setup")
-		return errors.New("no model selected")
+		return err
 	}
 	color.Cyan("Using model: %s", modelName)
 
 	// Step 5: Build AI client using the Factory
-	client, err := ai.Create(modelName, cfg.Models[modelName])
+	client, err := newClient(cfg, modelName)
 	if err != nil {
-		return fmt.Errorf("create model: %w", err)
+		return err
 	}
 
-	// Step 6: Build a Conventional Commit prompt
-	prompt := format.BuildPrompt(format.PromptInput{
-		Diff:         safe,
-		Types:        format.AllowedTypes,
-		MaxTitle:     72,
-		MaxBody:      100,
-		UserTypeHint: *flagType,
-	})
+	// Step 6: Build a Conventional Commit prompt. The current branch says
+	// nothing about a patch or range given on the command line.
+	var issueKey string
+	var trailers []string
+	if !external {
+		issueKey, trailers = branchIssue(ctx)
+	}
+	trailers = append(trailers, extraTrailers...)
+	if issueKey != "" {
+		color.Cyan("Issue from branch: %s", issueKey)
+	}
+	style := repoStyle(ctx)
+	var prompt string
+	if state.Op == git.OpMerge {
+		if prompt, err = mergePrompt(ctx, state, *flagMaxBytes); err != nil {
+			return err
+		}
+	} else {
+		prompt = format.BuildPrompt(format.PromptInput{
+			Diff:            safe,
+			Types:           format.AllowedTypes,
+			MaxTitle:        maxTitle,
+			MaxBody:         maxBody,
+			UserTypeHint:    *flagType,
+			PreviousMessage: redact(previous),
+			IssueKey:        issueKey,
+			PreparedMessage: redact(state.PreparedMessage),
+			Style:           style,
+			Context:         fullFiles,
+		})
+	}
 	if *flagDryRun {
 		fmt.Println("=== [SANITIZED DIFF] ===")
 		fmt.Println(safe)
@@ -137,29 +383,45 @@ func (a *App) Run(ctx context.Context, argv []string) error {
 		return nil
 	}
 
-	// Step 7: Generate message via Strategy client
-	spin := ui.NewSpinner("Generating commit message...")
-	spin.Start()
-	msg, genErr := client.Generate(ctx, prompt, *flagMaxTokens)
-	spin.Stop()
-	fmt.Println()
-	if genErr != nil || strings.TrimSpace(msg) == "" {
-		color.Yellow("AI failed or returned empty message. Falling back. err=%v", genErr)
-		msg = format.FallbackFromDiff(diff)
-	}
-
-	// Step 8: Normalize/validate to Conventional Commits constraints
-	msg = format.NormalizeMessage(msg, format.NormalizeOptions{
-		MaxTitle: 72,
-		MaxBody:  100,
-		Types:    format.AllowedTypes,
-		DefaultType: func() string {
-			if *flagType != "" {
-				return *flagType
-			}
-			return "chore"
-		}(),
-	})
+	// Normalize/validate to Conventional Commits constraints, then add
+	// trailers the model must not be able to drop. Only generated text is
+	// fitted to the learned style; an edited message keeps its scope and casing.
+	finalize := func(m, defaultType string, style *format.StyleProfile) string {
+		return format.AppendTrailers(format.NormalizeMessage(m, commitNormalizeOptions(defaultType, style, changes.Paths())), trailers)
+	}
+
+	prov := newProvenance(cfg, modelName, prompt, redactions)
+	var msg string
+	generated := state.Op != git.OpCherryPick // only generated messages get a provenance note
+	if state.Op == git.OpCherryPick {
+		// Step 7/8: A cherry-pick keeps its original message; regenerate is still available
+		if msg, err = cherryPickMessage(ctx, state, trailers); err != nil {
+			return err
+		}
+	} else {
+		// Step 7: Generate message via Strategy client, streaming it when
+		// someone is there to watch
+		var genErr error
+		msg, genErr = generateLive(ctx, client, "Generating commit message...", prompt, *flagMaxTokens, source.Stdin == "")
+		fmt.Println()
+		if errors.Is(genErr, errAborted) {
+			return genErr
+		}
+		if genErr != nil || strings.TrimSpace(msg) == "" {
+			color.Yellow("AI failed or returned empty message. Falling back. err=%v", genErr)
+			printGenErrorHint(modelName, genErr)
+			msg = format.FallbackFromDiff(changes)
+			prov.Fallback = true
+		}
+
+		// Step 8: Normalize and add trailers
+		msg = finalize(msg, *flagType, style)
+	}
+	if source.Stdin != "" {
+		// stdin held the patch, so there is nobody to ask
+		fmt.Println(msg)
+		return nil
+	}
 
 	// Step 9: Interactive approval loop
 	for {
@@ -178,28 +440,39 @@ func (a *App) Run(ctx context.Context, argv []string) error {
 				fmt.Println("\n[NO-COMMIT] Final message:\n" + msg)
 				return nil
 			}
-			return git.CommitWithMessage(ctx, msg)
+			if *flagAmend {
+				err = git.AmendWithMessage(ctx, msg, commitOpts)
+			} else {
+				err = git.CommitWithMessage(ctx, msg, commitOpts)
+			}
+			committed = err == nil
+			if committed && generated && (*flagProvenance || cfg.Provenance) {
+				recordProvenance(ctx, "HEAD", prov)
+			}
+			return err
 		case "e", "edit":
 			edited, err := ui.EditInEditor(msg) // opens $EDITOR or inline edit fallback
 			if err != nil {
 				return err
 			}
-			msg = format.NormalizeMessage(edited, format.NormalizeOptions{
-				MaxTitle: 72, MaxBody: 100, Types: format.AllowedTypes, DefaultType: "chore",
-			})
+			msg = finalize(edited, "", nil)
+			prov.Edited = true
 		case "r", "regenerate":
-			spin := ui.NewSpinner("Regenerating commit message...")
-			spin.Start()
-			newMsg, err := client.Generate(ctx, prompt, *flagMaxTokens)
-			spin.Stop()
+			newMsg, err := generateLive(ctx, client, "Regenerating commit message...", prompt, *flagMaxTokens, true)
 			fmt.Println()
+			if errors.Is(err, errAborted) {
+				color.Yellow("Regenerate aborted; keeping existing proposal.")
+				continue
+			}
 			if err != nil || strings.TrimSpace(newMsg) == "" {
 				color.Yellow("Regenerate failed; keeping existing proposal.")
+				printGenErrorHint(modelName, err)
 				continue
 			}
-			msg = format.NormalizeMessage(newMsg, format.NormalizeOptions{
-				MaxTitle: 72, MaxBody: 100, Types: format.AllowedTypes, DefaultType: "chore",
-			})
+			msg = finalize(newMsg, "", style)
+			generated = true
+			prov.Regenerations++
+			prov.Edited, prov.Fallback = false, false
 		case "c", "cancel":
 			return errors.New("cancelled by user")
 		default:
@@ -212,6 +485,7 @@ func (a *App) runSetup(ctx context.Context, argv []string) error {
 	fs := flag.NewFlagSet("gessage setup", flag.ContinueOnError)
 	fs.Usage = printSetupUsage
 	var flagModel = fs.String("model", "", "Model to configure (one of: "+strings.Join(ai.Known(), ", ")+")")
+	var flagProvider = fs.String("provider", "", "Provider to configure --model as a named instance of (e.g., openai-compatible)")
 	if err := fs.Parse(argv); err != nil {
 		if err == flag.ErrHelp {
 			return nil
@@ -219,6 +493,10 @@ func (a *App) runSetup(ctx context.Context, argv []string) error {
 		return err
 	}
 
+	if *flagProvider != "" && *flagModel == "" {
+		return errors.New("--provider needs --model <name> for the new instance")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -229,6 +507,7 @@ func (a *App) runSetup(ctx context.Context, argv []string) error {
 		modelName = *flagModel
 	} else {
 		// Interactive selector: show available models and which are already configured
+		registerInstances(cfg)
 		known := ai.Known()
 		if len(known) == 0 {
 			return errors.New("no models registered")
@@ -249,9 +528,27 @@ func (a *App) runSetup(ctx context.Context, argv []string) error {
 		modelName = known[idx]
 	}
 
-	prov, ok := ai.ProviderFor(modelName)
+	// A named instance remembers its provider so it can be registered again
+	// whenever it is used
+	base := strings.TrimSpace(*flagProvider)
+	if base == "" {
+		base = cfg.Models[modelName]["provider"]
+	}
+	if base == modelName {
+		base = ""
+	}
+	if base != "" {
+		if _, builtin := ai.ProviderFor(modelName); builtin && cfg.Models[modelName]["provider"] == "" {
+			return fmt.Errorf("%q is a built-in model; choose another name for the %s instance", modelName, base)
+		}
+	}
+	provName := modelName
+	if base != "" {
+		provName = base
+	}
+	prov, ok := ai.ProviderFor(provName)
 	if !ok {
-		return fmt.Errorf("unknown model %q; known: %v", modelName, ai.Known())
+		return fmt.Errorf("unknown model %q; known: %v", provName, ai.Known())
 	}
 
 	color.Cyan("Configuring model: %s", modelName)
@@ -259,6 +556,9 @@ func (a *App) runSetup(ctx context.Context, argv []string) error {
 	if err != nil {
 		return err
 	}
+	if base != "" {
+		mcfg["provider"] = base
+	}
 	if cfg.Models == nil {
 		cfg.Models = map[string]map[string]string{}
 	}
@@ -291,6 +591,7 @@ func (a *App) runDown(ctx context.Context, argv []string) error {
 	if *flagModel != "" {
 		modelName = *flagModel
 	} else {
+		registerInstances(cfg)
 		known := ai.Known()
 		if len(known) == 0 {
 			return errors.New("no models registered")
@@ -302,6 +603,9 @@ func (a *App) runDown(ctx context.Context, argv []string) error {
 		modelName = known[idx]
 	}
 
+	if err := registerInstance(cfg, modelName); err != nil {
+		return err
+	}
 	prov, ok := ai.ProviderFor(modelName)
 	if !ok {
 		return fmt.Errorf("unknown model %q; known: %v", modelName, ai.Known())
@@ -340,6 +644,7 @@ func (a *App) runDefault(ctx context.Context, argv []string) error {
 	// Choose provider
 	modelName := strings.TrimSpace(*flagModel)
 	if modelName == "" {
+		registerInstances(cfg)
 		known := ai.Known()
 		if len(known) == 0 {
 			return errors.New("no models registered")
@@ -361,6 +666,9 @@ func (a *App) runDefault(ctx context.Context, argv []string) error {
 	}
 
 	// Choose variant if provider exposes variants; else prompt free-form or use existing
+	if err := registerInstance(cfg, modelName); err != nil {
+		return err
+	}
 	prov, ok := ai.ProviderFor(modelName)
 	if !ok {
 		return fmt.Errorf("unknown model %q; known: %v", modelName, ai.Known())
@@ -371,7 +679,20 @@ func (a *App) runDefault(ctx context.Context, argv []string) error {
 	}
 	version := strings.TrimSpace(*flagVersion)
 	if version == "" {
-		if prov.Variants != nil {
+		if prov.Discover != nil {
+			models, err := prov.Discover(ctx, mcfg)
+			if err != nil {
+				color.Yellow("Could not list models for %s: %v", modelName, err)
+			}
+			if len(models) > 0 {
+				idx, selErr := ui.Select("Select default model for "+modelName+":", models, 0)
+				if selErr != nil {
+					return selErr
+				}
+				version = models[idx]
+			}
+		}
+		if version == "" && prov.Variants != nil {
 			variants := prov.Variants()
 			if len(variants) > 0 {
 				idx, selErr := ui.Select("Select default model for "+modelName+":", variants, 0)
@@ -429,21 +750,43 @@ func printRootUsage() {
 	fmt.Println()
 
 	section.Println("Usage:")
-	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" [flags]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" [-C <dir>] [flags] [<pathspec>...] [-- <git commit args>]"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" setup [--model <name>]"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" down [--model <name>]"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" default [--model <name>] [--version <id>]"))
-	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" help [setup|down|default]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" hook install|uninstall|status [--commit-msg]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" reword [flags] <range>"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" pr [--base <branch>] [flags]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" changelog [--from <rev>] [--to <rev>] [--write]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" release [--tag [--dry-run]] [--pre <id>] [--tag-prefix <prefix>]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" split [flags]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" squash [--apply] <range>"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" provenance [<rev>]"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" style learn|show|forget"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" lint [--fix] --range <A..B> | --file <path> | -"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" help [setup|down|default|hook|reword|pr|changelog|release|split|squash|provenance|style|lint]"))
 	fmt.Println()
 
 	section.Println("Subcommands:")
 	fmt.Println("  ", cmd.Sprint("setup"), dim.Sprint("    Interactive model selection, installation, and configuration"))
 	fmt.Println("  ", cmd.Sprint("down"), dim.Sprint("     Stop or unload local model resources (e.g., Ollama service/model)"))
 	fmt.Println("  ", cmd.Sprint("default"), dim.Sprint("  Set default model and its version/identifier"))
+	fmt.Println("  ", cmd.Sprint("hook"), dim.Sprint("     Install a prepare-commit-msg hook so plain 'git commit' gets a generated message,"))
+	fmt.Println("  ", dim.Sprint("          or a commit-msg hook that lints every message"))
+	fmt.Println("  ", cmd.Sprint("reword"), dim.Sprint("   Regenerate messages for a range of existing commits and rewrite them"))
+	fmt.Println("  ", cmd.Sprint("pr"), dim.Sprint("       Generate a pull request title and description for the current branch"))
+	fmt.Println("  ", cmd.Sprint("changelog"), dim.Sprint("Build a CHANGELOG.md section from Conventional Commit history (no AI)"))
+	fmt.Println("  ", cmd.Sprint("release"), dim.Sprint("  Compute the next semver from commits and tag it with generated release notes"))
+	fmt.Println("  ", cmd.Sprint("split"), dim.Sprint("    Split a large staged diff into several logical commits"))
+	fmt.Println("  ", cmd.Sprint("squash"), dim.Sprint("   Summarize a range of commits as one message, optionally squashing them"))
+	fmt.Println("  ", cmd.Sprint("provenance"), dim.Sprint("Show how a commit's message was generated (git note)"))
+	fmt.Println("  ", cmd.Sprint("style"), dim.Sprint("    Learn the repository's commit style from its history and follow it"))
+	fmt.Println("  ", cmd.Sprint("lint"), dim.Sprint("     Check commit messages against the Conventional Commit rules (CI, commit-msg hook)"))
 	fmt.Println("  ", cmd.Sprint("help"), dim.Sprint("     Show this help, or help for a subcommand"))
 	fmt.Println()
 
 	section.Println("Flags:")
+	fmt.Println("  ", flagC.Sprint("-C dir"), dim.Sprint("             Run as if started in dir (must come first; applies to every subcommand)"))
 	fmt.Println("  ", flagC.Sprint("--model string"), dim.Sprint("     AI model to use (e.g., gpt4-o, openrouter, ollama)"))
 	fmt.Println("  ", flagC.Sprint("--auto"), dim.Sprint("             Auto-select model based on diff size (default true)"))
 	fmt.Println("  ", flagC.Sprint("--type string"), dim.Sprint("      Conventional commit type override (feat, fix, refactor, docs, chore, style, test, perf)"))
@@ -451,6 +794,21 @@ func printRootUsage() {
 	fmt.Println("  ", flagC.Sprint("--max-tokens int"), dim.Sprint("   Max tokens for AI generation (default 512)"))
 	fmt.Println("  ", flagC.Sprint("--dry-run"), dim.Sprint("          Print sanitized diff and prompt; do not call AI"))
 	fmt.Println("  ", flagC.Sprint("--max-bytes int"), dim.Sprint("    Max diff bytes to send to AI after sanitization (default 100000)"))
+	fmt.Println("  ", flagC.Sprint("--amend"), dim.Sprint("            Rewrite HEAD's message from its diff plus staged changes and amend it"))
+	fmt.Println("  ", flagC.Sprint("-s, --signoff"), dim.Sprint("      Add a Signed-off-by trailer (DCO)"))
+	fmt.Println("  ", flagC.Sprint("-S, --gpg-sign"), dim.Sprint("     GPG-sign the commit"))
+	fmt.Println("  ", flagC.Sprint("--no-verify"), dim.Sprint("        Bypass pre-commit and commit-msg hooks"))
+	fmt.Println("  ", flagC.Sprint("--author string"), dim.Sprint("    Override the commit author (\"Name <email>\")"))
+	fmt.Println("  ", flagC.Sprint("--trailer k=v"), dim.Sprint("      Add a trailer, e.g. --trailer Reviewed-by=\"Ann <ann@x.io>\" (repeatable)"))
+	fmt.Println("  ", flagC.Sprint("--pair alias"), dim.Sprint("       Add Co-authored-by for a pair from the config's \"pairs\" map (repeatable)"))
+	fmt.Println("  ", flagC.Sprint("--all"), dim.Sprint("              Stage modified and deleted tracked files first, like 'git commit -a'"))
+	fmt.Println("  ", flagC.Sprint("--include-untracked"), dim.Sprint("Also stage untracked files (respects .gitignore)"))
+	fmt.Println("  ", flagC.Sprint("--provenance"), dim.Sprint("       Record provider, model and prompt hash in a git note ("+git.NotesRef+")"))
+	fmt.Println("  ", flagC.Sprint("--context value"), dim.Sprint("    Code around each hunk: 'function' or a number of lines; tiny changes add whole files"))
+	fmt.Println("  ", flagC.Sprint("--diff -"), dim.Sprint("           Describe the patch read from stdin instead of the index (implies --no-commit)"))
+	fmt.Println("  ", flagC.Sprint("--diff-file path"), dim.Sprint("   Describe a patch file: git diff, format-patch mails, diff -u, svn or hg (implies --no-commit)"))
+	fmt.Println("  ", flagC.Sprint("--range A..B"), dim.Sprint("       Describe a revision range from its merge base, or one commit (implies --no-commit)"))
+	fmt.Println("  ", flagC.Sprint("-- args"), dim.Sprint("            Pass options after -- straight to 'git commit' (values with =); pathspecs go before it"))
 	fmt.Println()
 
 	section.Println("Models (installed/available):")
@@ -470,6 +828,20 @@ func printRootUsage() {
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --model openrouter"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --model ollama"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --dry-run"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --amend"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" -s --pair alice --trailer Reviewed-by=\"Bob <bob@x.io>\""))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" -- --date=now --cleanup=strip"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --all internal/ai"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --range origin/main..HEAD"))
+	fmt.Println("  ", cmd.Sprint("git"), dim.Sprint(" format-patch -1 --stdout | gessage --diff -"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" hook install"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" reword origin/main..HEAD"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" pr --base main"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" changelog --version 1.2.0 --write"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" release --tag --dry-run"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" split --dry-run"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" squash --apply origin/main"))
+	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" provenance HEAD~2"))
 	fmt.Println("  ", cmd.Sprint("gessage"), dim.Sprint(" --version | -v"))
 }
 
@@ -478,19 +850,25 @@ func printSetupUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  gessage setup [--model <name>]")
+	fmt.Println("  gessage setup --model <name> --provider <provider>")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("  --model string     Model to configure (one of:", strings.Join(ai.Known(), ", "), ")")
+	fmt.Println("  --provider string  Configure <name> as a separate instance of a provider (e.g., openai-compatible)")
 	fmt.Println()
 	fmt.Println("Notes:")
 	fmt.Println("  - 'ollama' setup can install the Ollama CLI (with confirmation), start the local service, and pull the selected model.")
 	fmt.Println("  - 'gpt4-o' setup asks for your OpenAI API key and preferred model name.")
 	fmt.Println("  - 'openrouter' setup asks for your OpenRouter API key and lets you pick a free model (e.g., qwen/qwen3-coder:free).")
+	fmt.Println("  - 'openai-compatible' setup asks for a base URL, optional API key and extra headers, then lists the server's models.")
+	fmt.Println("  - Instances are saved with a \"provider\" key and can then be used like any model: --model <name>.")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  gessage setup --model ollama")
 	fmt.Println("  gessage setup --model gpt4-o")
 	fmt.Println("  gessage setup --model openrouter")
+	fmt.Println("  gessage setup --model lmstudio --provider openai-compatible")
+	fmt.Println("  gessage setup --model vllm-team --provider openai-compatible")
 }
 
 func printDownUsage() {
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChdirFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		cwd     string // relative to the repository
+		args    []string
+		wantErr string
+	}{
+		{name: "from outside", cwd: "..", args: []string{"-C", "repo"}},
+		{name: "repeated", cwd: "../..", args: []string{"-C", "parent", "-C", "repo"}},
+		{name: "from a subdirectory", cwd: "sub", args: []string{"-C", ".."}},
+		{name: "empty", args: []string{"-C", ""}},
+		{name: "missing directory", args: []string{"-C", "nope"}, wantErr: "cannot change to nope"},
+		{name: "outside a repository", cwd: "..", wantErr: "not inside a git working tree (use -C <dir> to point at one)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo(t)
+			repo := filepath.Join(t.TempDir(), "parent", "repo")
+			gitT(t, "init", "-q", "-b", "main", repo)
+			t.Chdir(repo)
+			commitFile(t, "sub/a.txt", "a\n", "feat: add a")
+			writeFile(t, "staged.txt", "staged\n")
+			gitT(t, "add", "staged.txt")
+			t.Chdir(filepath.Join(repo, tt.cwd))
+			useFakeModel(t, "feat: add staged")
+
+			var err error
+			out := withStdio(t, "", func() {
+				err = NewApp().Run(context.Background(), append(tt.args, "--model", "fake", "--dry-run"))
+			})
+			if got := errString(err); !strings.HasPrefix(got, tt.wantErr) || (tt.wantErr == "") != (err == nil) {
+				t.Fatalf("Run(%q) error = %q, want %q", tt.args, got, tt.wantErr)
+			}
+			if got := describedFiles(out); err == nil && strings.Join(got, " ") != "staged.txt" {
+				t.Errorf("Run(%q) described %q, want staged.txt", tt.args, got)
+			}
+		})
+	}
+}
+
+func TestChdirFlagWithoutDirectory(t *testing.T) {
+	if err := NewApp().Run(context.Background(), []string{"-C"}); errString(err) != "-C requires a directory" {
+		t.Errorf("Run(-C) = %v, want %q", err, "-C requires a directory")
+	}
+}
+
+func TestCommitInLinkedWorktree(t *testing.T) {
+	dir := testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	wt := dir + "-wt"
+	gitT(t, "worktree", "add", "-q", "-b", "topic", wt)
+	writeFile(t, filepath.Join(wt, "b.txt"), "b\n")
+	gitT(t, "-C", wt, "add", "b.txt")
+
+	out, err := runGessage(t, "a\n", []string{"feat: add b"}, "-C", wt, "--model", "fake")
+	if err != nil {
+		t.Fatalf("gessage -C %s: %v\n%s", wt, err, out)
+	}
+	if got := gitT(t, "log", "-1", "--format=%s", "topic"); got != "feat: add b" {
+		t.Errorf("topic's last commit = %q, want the generated one", got)
+	}
+	if got := gitT(t, "log", "-1", "--format=%s", "main"); got != "feat: add a" {
+		t.Errorf("main moved to %q", got)
+	}
+}
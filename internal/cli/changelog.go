@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/changelog"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+func (a *App) runChangelog(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage changelog", flag.ContinueOnError)
+	fs.Usage = printChangelogUsage
+	var (
+		flagFrom    = fs.String("from", "", "Start of the range, exclusive (default: latest tag reachable from --to)")
+		flagTo      = fs.String("to", "HEAD", "End of the range, inclusive")
+		flagVersion = fs.String("version", "Unreleased", "Version for the section heading")
+		flagDate    = fs.String("date", "", "Release date YYYY-MM-DD (default: committer date of --to)")
+		flagFile    = fs.String("file", "CHANGELOG.md", "Changelog file to update with --write")
+		flagWrite   = fs.Bool("write", false, "Insert the section into --file instead of printing it")
+		flagRepoURL = fs.String("repo-url", "", "Web URL used to link commit hashes (default: derived from origin)")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	from := *flagFrom
+	if from == "" {
+		from = git.LatestTag(ctx, *flagTo)
+	}
+	revRange := *flagTo
+	if from != "" {
+		revRange = from + ".." + *flagTo
+	}
+	commits, err := git.Log(ctx, "--no-merges", revRange)
+	if err != nil {
+		return err
+	}
+
+	date := *flagDate
+	if date == "" {
+		if date, err = git.CommitDate(ctx, *flagTo); err != nil {
+			return err
+		}
+	}
+	repoURL := *flagRepoURL
+	if repoURL == "" {
+		repoURL, _ = git.RemoteWebURL(ctx, "origin") // unlinked hashes are fine without a remote
+	}
+
+	section := changelog.Render(commits, changelog.Options{
+		Version: *flagVersion,
+		Date:    date,
+		RepoURL: repoURL,
+	})
+	if !*flagWrite {
+		fmt.Print(section)
+		return nil
+	}
+
+	existing, err := os.ReadFile(*flagFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(*flagFile, []byte(changelog.Insert(string(existing), section)), 0o644); err != nil {
+		return err
+	}
+	color.Green("Updated %s (%d commits in %s)", *flagFile, len(commits), revRange)
+	return nil
+}
+
+func printChangelogUsage() {
+	fmt.Println("gessage changelog - build a Keep a Changelog section from Conventional Commit history")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage changelog [--from <rev>] [--to <rev>] [--version <v>] [--write [--file <path>]]")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --from string      Start of the range, exclusive (default: latest tag reachable from --to)")
+	fmt.Println("  --to string        End of the range, inclusive (default HEAD)")
+	fmt.Println("  --version string   Version for the section heading (default Unreleased)")
+	fmt.Println("  --date string      Release date YYYY-MM-DD (default: committer date of --to)")
+	fmt.Println("  --file string      Changelog file to update with --write (default CHANGELOG.md)")
+	fmt.Println("  --write            Insert the section into --file instead of printing it")
+	fmt.Println("  --repo-url string  Web URL used to link commit hashes (default: derived from origin)")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - No AI is involved; the same range always produces the same section.")
+	fmt.Println("  - Breaking changes ('!' or BREAKING CHANGE footers) are listed first.")
+	fmt.Println("  - Commits that are not Conventional Commits are skipped.")
+	fmt.Println("  - An existing section for the same version is replaced.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage changelog")
+	fmt.Println("  gessage changelog --from v1.2.0 --version 1.3.0 --write")
+}
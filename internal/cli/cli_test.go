@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+)
+
+// replyEnv makes the test binary run as gessage (see runGessage), with the
+// model "fake" answering each prompt with the next of these replies,
+// separated by replySep.
+const (
+	replyEnv = "GESSAGE_TEST_REPLIES"
+	replySep = "\x1e"
+)
+
+func TestMain(m *testing.M) {
+	if replies, ok := os.LookupEnv(replyEnv); ok {
+		c := &fakeClient{replies: strings.Split(replies, replySep)}
+		ai.Register("fake", ai.Provider{Constructor: func(map[string]string) (ai.Client, error) { return c, nil }})
+		if err := NewApp().Run(context.Background(), os.Args[1:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runGessage runs gessage with args in a child process, in the working
+// directory, answering its prompts with input. The model "fake" replies with
+// replies in turn, repeating the last one. It returns everything printed.
+func runGessage(t *testing.T, input string, replies []string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), replyEnv+"="+strings.Join(replies, replySep))
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// testRepo creates an empty repository in a temporary directory, isolated
+// from the user's git and gessage config, and makes it the working directory.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(dir)
+	gitT(t, "init", "-q", "-b", "main")
+	return dir
+}
+
+// gitT runs git in the working directory and returns its trimmed output.
+func gitT(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeFile writes content to path (relative to the working directory),
+// creating parent directories.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commitFile writes path and commits it with msg.
+func commitFile(t *testing.T, path, content, msg string) {
+	t.Helper()
+	writeFile(t, path, content)
+	gitT(t, "add", path)
+	gitT(t, "commit", "-q", "-m", msg)
+}
+
+// withStdio runs fn with stdin reading input and returns what fn printed to
+// stdout, colored output included.
+func withStdio(t *testing.T, input string, fn func()) string {
+	t.Helper()
+	in := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(in, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldIn, oldOut, oldColor := os.Stdin, os.Stdout, color.Output
+	os.Stdin, os.Stdout, color.Output = stdin, w, w
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	defer func() { os.Stdin, os.Stdout, color.Output = oldIn, oldOut, oldColor }()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// fakeClient is a model that answers prompts with replies in turn,
+// repeating the last one.
+type fakeClient struct {
+	replies []string
+	prompts []string
+}
+
+func (c *fakeClient) Generate(_ context.Context, prompt string, _ int) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	reply := c.replies[min(len(c.prompts), len(c.replies))-1]
+	return reply, nil
+}
+
+// useFakeModel registers a fake model under the name "fake", for --model fake.
+func useFakeModel(t *testing.T, replies ...string) *fakeClient {
+	t.Helper()
+	c := &fakeClient{replies: replies}
+	ai.Register("fake", ai.Provider{Constructor: func(map[string]string) (ai.Client, error) { return c, nil }})
+	return c
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// tinyChangeLines is the largest change (added plus deleted lines) for which
+// the full post-image of the touched files is added to the prompt.
+const tinyChangeLines = 20
+
+// parseContext reads a --context value: "function" for the enclosing
+// function, a number for that many lines around each hunk, "" for git's default.
+func parseContext(s string) (git.ContextOptions, error) {
+	switch s = strings.TrimSpace(s); s {
+	case "":
+		return git.ContextOptions{}, nil
+	case "function":
+		return git.ContextOptions{Function: true}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return git.ContextOptions{}, fmt.Errorf("invalid context %q; use \"function\" or a number of lines (1 or more)", s)
+	}
+	return git.ContextOptions{Lines: n}, nil
+}
+
+// widenContext returns d with the surrounding code opt asks for, as long as
+// the wider diff fits in maxBytes; otherwise d is returned unchanged.
+func widenContext(ctx context.Context, d diff.Diff, opt git.ContextOptions, maxBytes int) diff.Diff {
+	if opt.IsZero() {
+		return d
+	}
+	wide, err := git.WithContext(ctx, d, opt)
+	if errors.Is(err, git.ErrNotFromRepository) {
+		color.Yellow("--context needs a diff from this repository; ignored for patches.")
+		return d
+	}
+	if err != nil {
+		color.Yellow("Could not widen the diff context: %v", err)
+		return d
+	}
+	if maxBytes > 0 && len(wide.String()) > maxBytes {
+		color.Yellow("The diff with more context exceeds --max-bytes; using the plain diff.")
+		return d
+	}
+	return wide
+}
+
+// postImages returns the full content of the files a tiny change touches,
+// redacted, while the total stays within budget bytes. Deleted, binary and
+// submodule entries have no text to show.
+func postImages(ctx context.Context, d diff.Diff, budget int) []format.FileContent {
+	if added, deleted := d.Stats(); added+deleted > tinyChangeLines {
+		return nil
+	}
+	var out []format.FileContent
+	for _, f := range d.All() {
+		if f.Status == diff.StatusDeleted || f.Binary || f.Submodule {
+			continue
+		}
+		content, err := git.PostImage(ctx, d, f.Path())
+		if err != nil {
+			continue
+		}
+		content = redact(content)
+		if len(content) > budget {
+			continue
+		}
+		budget -= len(content)
+		out = append(out, format.FileContent{Path: f.Path(), Content: content})
+	}
+	return out
+}
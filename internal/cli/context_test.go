@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// handlerFile is a Go file whose function body is long enough that a change
+// at its end is more than three lines away from the signature.
+func handlerFile(last string) string {
+	var b strings.Builder
+	b.WriteString("package main\n\n// dsn: password=hunter2hunter2\nfunc handler() {\n")
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&b, "\tstep%d()\n", i)
+	}
+	b.WriteString("\t" + last + "\n}\n")
+	return b.String()
+}
+
+func TestContextFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		big     bool     // stage a change too large for full files
+		want    []string // in the dry-run output
+		not     []string
+		wantErr string
+	}{
+		{
+			name: "default",
+			not:  []string{"\n func handler() {\n", "BEGIN CONTEXT"},
+		},
+		{
+			name: "function",
+			args: []string{"--context", "function"},
+			want: []string{"\n func handler() {\n", "=== BEGIN CONTEXT main.go ===\n", "\tdone()\n}\n=== END CONTEXT main.go ==="},
+		},
+		{
+			name: "lines",
+			args: []string{"--context", "5"},
+			want: []string{"\n \tstep8()\n", "=== BEGIN CONTEXT main.go ==="},
+			not:  []string{"\n \tstep7()\n"},
+		},
+		{
+			name: "large change",
+			args: []string{"--context", "function"},
+			big:  true,
+			want: []string{"\n func handler() {\n"},
+			not:  []string{"BEGIN CONTEXT"},
+		},
+		{name: "invalid", args: []string{"--context", "0"}, wantErr: `invalid context "0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo(t)
+			commitFile(t, "main.go", handlerFile("return"), "feat: add handler")
+			last := "done()"
+			if tt.big {
+				last += strings.Repeat("\n\tmore()", 30)
+			}
+			writeFile(t, "main.go", handlerFile(last))
+			gitT(t, "add", "main.go")
+			useFakeModel(t, "feat: finish the handler")
+
+			var err error
+			out := withStdio(t, "", func() {
+				err = NewApp().Run(context.Background(), append([]string{"--model", "fake", "--dry-run"}, tt.args...))
+			})
+			if got := errString(err); !strings.HasPrefix(got, tt.wantErr) || (tt.wantErr == "") != (err == nil) {
+				t.Fatalf("Run(%q) error = %q, want %q", tt.args, got, tt.wantErr)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("Run(%q) output does not contain %q:\n%s", tt.args, s, out)
+				}
+			}
+			for _, s := range append(tt.not, "hunter2hunter2") {
+				if strings.Contains(out, s) {
+					t.Errorf("Run(%q) output contains %q:\n%s", tt.args, s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestContextIgnoredForPatches(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "main.go", handlerFile("return"), "feat: add handler")
+	writeFile(t, "main.go", handlerFile("done()"))
+	patch := filepath.Join(t.TempDir(), "change.patch")
+	if err := os.WriteFile(patch, []byte(gitT(t, "diff")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	useFakeModel(t, "feat: finish the handler")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run", "--context", "function", "--diff-file", patch})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "--context needs a diff from this repository; ignored for patches.") {
+		t.Errorf("no warning that --context was ignored:\n%s", out)
+	}
+	if !strings.Contains(out, "+\tdone()") || strings.Contains(out, "BEGIN CONTEXT") {
+		t.Errorf("patch not described as given:\n%s", out)
+	}
+}
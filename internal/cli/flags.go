@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stringList is a repeatable string flag (e.g. --trailer a=b --trailer c=d).
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ", ") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// splitPassthrough separates the arguments gessage parses from the ones after
+// a literal `--`, which are handed to `git commit` unchanged (see
+// checkPassthrough).
+func splitPassthrough(argv []string) (own, passthrough []string) {
+	for i, arg := range argv {
+		if arg == "--" {
+			return argv[:i], argv[i+1:]
+		}
+	}
+	return argv, nil
+}
+
+// checkPassthrough rejects arguments after `--` that are not options. git
+// would read them as pathspecs and commit only those paths, while the message
+// describes everything staged; pathspecs go before `--` instead.
+func checkPassthrough(passthrough []string) error {
+	for _, arg := range passthrough {
+		if !strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("%q after -- is not a git commit option; pass pathspecs before -- (gessage %s) "+
+				"and option values with = (--date=now)", arg, arg)
+		}
+	}
+	return nil
+}
+
+// wantsHelp reports whether argv asks for usage before any `--`.
+func wantsHelp(argv []string) bool {
+	own, _ := splitPassthrough(argv)
+	for _, arg := range own {
+		switch arg {
+		case "-h", "-help", "--help":
+			return true
+		}
+	}
+	return false
+}
+
+// readsPatch reports whether argv passes the root command a patch (--diff or
+// --diff-file), which can be described outside any repository.
+func readsPatch(argv []string) bool {
+	own, _ := splitPassthrough(argv)
+	for _, arg := range own {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "diff" || name == "diff-file" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitPassthrough(t *testing.T) {
+	tests := []struct {
+		argv             []string
+		own, passthrough []string
+	}{
+		{argv: nil},
+		{argv: []string{"--all", "internal/ai"}, own: []string{"--all", "internal/ai"}},
+		{argv: []string{"--all", "--", "--date=now"}, own: []string{"--all"}, passthrough: []string{"--date=now"}},
+		{argv: []string{"--", "--", "-v"}, own: []string{}, passthrough: []string{"--", "-v"}},
+		{argv: []string{"a", "--"}, own: []string{"a"}, passthrough: []string{}},
+	}
+	for _, tt := range tests {
+		own, passthrough := splitPassthrough(tt.argv)
+		if !reflect.DeepEqual(own, tt.own) || !reflect.DeepEqual(passthrough, tt.passthrough) {
+			t.Errorf("splitPassthrough(%q) = %q, %q, want %q, %q", tt.argv, own, passthrough, tt.own, tt.passthrough)
+		}
+	}
+}
+
+func TestCheckPassthrough(t *testing.T) {
+	for _, args := range [][]string{nil, {"--date=now"}, {"--allow-empty", "-v", "--cleanup=strip"}} {
+		if err := checkPassthrough(args); err != nil {
+			t.Errorf("checkPassthrough(%q) = %v", args, err)
+		}
+	}
+	for _, args := range [][]string{{"internal/ai"}, {"--date", "now"}, {"-v", "."}} {
+		if err := checkPassthrough(args); err == nil {
+			t.Errorf("checkPassthrough(%q) = nil, want an error", args)
+		}
+	}
+}
+
+func TestWantsHelpAndReadsPatch(t *testing.T) {
+	tests := []struct {
+		argv        []string
+		help, patch bool
+	}{
+		{argv: []string{"--help"}, help: true},
+		{argv: []string{"-h", "--diff", "-"}, help: true, patch: true},
+		{argv: []string{"--", "--help"}},
+		{argv: []string{"--diff=-"}, patch: true},
+		{argv: []string{"--diff-file", "x.patch"}, patch: true},
+		{argv: []string{"-diff-file=x.patch"}, patch: true},
+		{argv: []string{"diff"}},
+		{argv: []string{"--all", "--", "--diff"}},
+	}
+	for _, tt := range tests {
+		if got := wantsHelp(tt.argv); got != tt.help {
+			t.Errorf("wantsHelp(%q) = %v, want %v", tt.argv, got, tt.help)
+		}
+		if got := readsPatch(tt.argv); got != tt.patch {
+			t.Errorf("readsPatch(%q) = %v, want %v", tt.argv, got, tt.patch)
+		}
+	}
+}
+
+func TestRootRejectsPathsAfterDashDash(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	writeFile(t, "a.txt", "b\n")
+	gitT(t, "add", "a.txt")
+	useFakeModel(t, "fix: change a")
+
+	err := NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run", "--", "a.txt"})
+	if err == nil || !strings.Contains(err.Error(), `"a.txt" after -- is not a git commit option`) {
+		t.Errorf("Run(-- a.txt) = %v, want the pathspec rejected", err)
+	}
+	withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run", "--", "--date=now"})
+	})
+	if err != nil {
+		t.Errorf("Run(-- --date=now) = %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+const (
+	prepareCommitMsgHook = "prepare-commit-msg"
+	commitMsgHook        = "commit-msg"
+	// hookMarker identifies hook scripts written by gessage so we never
+	// overwrite or delete a hook we did not install.
+	hookMarker = "# installed by gessage"
+	// chainedSuffix is appended to a pre-existing hook when we install ours;
+	// our script runs it first and uninstall moves it back.
+	chainedSuffix = ".gessage-chained"
+)
+
+// hookScript runs any chained hook, skips sources where git already has a
+// message, and never blocks the commit if gessage itself fails.
+const hookScript = `#!/bin/sh
+` + hookMarker + `; remove with: gessage hook uninstall
+chained="$0` + chainedSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+case "$2" in
+	merge|squash|commit|message) exit 0 ;;
+esac
+GESSAGE=%s
+[ -x "$GESSAGE" ] || GESSAGE=gessage
+"$GESSAGE" hook run "$@" </dev/null || true
+`
+
+// lintHookScript rejects messages that fail `gessage lint`, after any chained
+// hook. It lets the commit through when gessage is not installed.
+const lintHookScript = `#!/bin/sh
+` + hookMarker + `; remove with: gessage hook uninstall --commit-msg
+chained="$0` + chainedSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+GESSAGE=%s
+[ -x "$GESSAGE" ] || GESSAGE=gessage
+command -v "$GESSAGE" >/dev/null 2>&1 || exit 0
+exec "$GESSAGE" lint --file "$1"
+`
+
+func (a *App) runHook(ctx context.Context, argv []string) error {
+	if len(argv) == 0 {
+		printHookUsage()
+		return errors.New("missing hook action (install, uninstall or status)")
+	}
+	action, rest := argv[0], argv[1:]
+	if action == "run" {
+		return a.runHookRun(ctx, rest)
+	}
+
+	fs := flag.NewFlagSet("gessage hook "+action, flag.ContinueOnError)
+	fs.Usage = printHookUsage
+	var (
+		flagForce     = fs.Bool("force", false, "Replace an existing gessage hook even if it looks modified")
+		flagCommitMsg = fs.Bool("commit-msg", false, "Manage the commit-msg hook that lints messages instead")
+	)
+	if err := fs.Parse(rest); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	dir, err := git.HooksDir(ctx)
+	if err != nil {
+		return err
+	}
+	name, script := prepareCommitMsgHook, hookScript
+	if *flagCommitMsg {
+		name, script = commitMsgHook, lintHookScript
+	}
+	path := filepath.Join(dir, name)
+
+	switch action {
+	case "install":
+		return installHook(path, script, *flagForce)
+	case "uninstall":
+		return uninstallHook(path)
+	case "status":
+		if err := hookStatus(filepath.Join(dir, prepareCommitMsgHook)); err != nil {
+			return err
+		}
+		return hookStatus(filepath.Join(dir, commitMsgHook))
+	default:
+		printHookUsage()
+		return fmt.Errorf("unknown hook action %q", action)
+	}
+}
+
+func installHook(path, script string, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	ours, exists := isGessageHook(path)
+	if exists && ours && !force {
+		color.Yellow("gessage hook already installed at %s", path)
+		return nil
+	}
+	if exists && !ours {
+		chained := path + chainedSuffix
+		if _, err := os.Stat(chained); err == nil {
+			return fmt.Errorf("%s already exists; refusing to overwrite it", chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return fmt.Errorf("chain existing hook: %w", err)
+		}
+		color.Yellow("Existing hook moved to %s and will run before gessage", chained)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "gessage"
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(script, shellQuote(exe))), 0o755); err != nil {
+		return err
+	}
+	color.Green("Installed %s hook at %s", filepath.Base(path), path)
+	return nil
+}
+
+func uninstallHook(path string) error {
+	ours, exists := isGessageHook(path)
+	if !exists {
+		color.Yellow("No %s hook installed", filepath.Base(path))
+		return nil
+	}
+	if !ours {
+		return fmt.Errorf("%s was not installed by gessage; leaving it untouched", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	chained := path + chainedSuffix
+	if _, err := os.Stat(chained); err == nil {
+		if err := os.Rename(chained, path); err != nil {
+			return fmt.Errorf("restore chained hook: %w", err)
+		}
+		color.Green("Removed gessage hook and restored the previous hook")
+		return nil
+	}
+	color.Green("Removed gessage hook from %s", path)
+	return nil
+}
+
+func hookStatus(path string) error {
+	name := filepath.Base(path)
+	ours, exists := isGessageHook(path)
+	switch {
+	case !exists:
+		fmt.Printf("%s: not installed (%s)\n", name, path)
+	case ours:
+		fmt.Printf("%s: installed (%s)\n", name, path)
+		if _, err := os.Stat(path + chainedSuffix); err == nil {
+			fmt.Printf("  chained: %s\n", path+chainedSuffix)
+		}
+	default:
+		fmt.Printf("%s: foreign hook present, gessage not installed (%s)\n", name, path)
+	}
+	return nil
+}
+
+// isGessageHook reports whether a hook exists at path and whether it was
+// written by gessage.
+func isGessageHook(path string) (ours, exists bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false, false
+	}
+	return strings.Contains(string(b), hookMarker), true
+}
+
+// runHookRun is invoked by the installed hook as
+// `gessage hook run <msg-file> [<source> [<sha>]]`. It runs the generation
+// pipeline without any interaction and prepends the result to the message
+// file, keeping git's comment template below it.
+func (a *App) runHookRun(ctx context.Context, argv []string) error {
+	if len(argv) == 0 {
+		return errors.New("usage: gessage hook run <msg-file> [<source> [<sha>]]")
+	}
+	msgFile := argv[0]
+	source := ""
+	if len(argv) > 1 {
+		source = argv[1]
+	}
+	switch source {
+	case "merge", "squash", "commit", "message":
+		return nil
+	}
+
+	diff, err := git.GetStagedDiff(ctx)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	contextOpts, err := parseContext(cfg.Context)
+	if err != nil {
+		return err
+	}
+	safe, _ := prepareDiff(ctx, widenContext(ctx, diff, contextOpts, 100_000), 100_000)
+	var fullFiles []format.FileContent
+	if !contextOpts.IsZero() {
+		fullFiles = postImages(ctx, diff, 100_000-len(safe.String()))
+	}
+	modelName, err := resolveModelName(cfg, "", true, len(safe.String()))
+	if err != nil {
+		return err
+	}
+	client, err := newClient(cfg, modelName)
+	if err != nil {
+		return err
+	}
+
+	issueKey, trailers := branchIssue(ctx)
+	style := repoStyle(ctx)
+	prompt := format.BuildPrompt(format.PromptInput{
+		Diff:     safe,
+		Types:    format.AllowedTypes,
+		MaxTitle: maxTitle,
+		MaxBody:  maxBody,
+		IssueKey: issueKey,
+		Style:    style,
+		Context:  fullFiles,
+	})
+	msg, err := client.Generate(ctx, prompt, 512)
+	if err != nil || strings.TrimSpace(msg) == "" {
+		// Leave the file alone so the user can write the message by hand.
+		if hint := genErrorHint(modelName, err); hint != "" {
+			return fmt.Errorf("gessage: generation failed, leaving message empty: %v\n%s", err, hint)
+		}
+		return fmt.Errorf("gessage: generation failed, leaving message empty: %v", err)
+	}
+	msg = format.AppendTrailers(format.NormalizeMessage(msg, commitNormalizeOptions("", style, diff.Paths())), trailers)
+
+	existing, err := os.ReadFile(msgFile)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(msgFile, []byte(msg+"\n"+string(existing)), 0o644)
+}
+
+func printHookUsage() {
+	fmt.Println("gessage hook - manage the prepare-commit-msg and commit-msg git hooks")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage hook install [--commit-msg] [--force]")
+	fmt.Println("  gessage hook uninstall [--commit-msg]")
+	fmt.Println("  gessage hook status")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --commit-msg       Manage the commit-msg hook, which rejects messages failing 'gessage lint'")
+	fmt.Println("  --force            Rewrite the gessage hook even if one is already installed")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - The hook is written to core.hooksPath when set, otherwise to the repository's hooks directory.")
+	fmt.Println("  - An existing hook is kept as <hook>" + chainedSuffix + " and runs first.")
+	fmt.Println("  - The hook does nothing for merge, squash, amend (-c/-C) and -m/-F commits.")
+	fmt.Println("  - If generation fails the commit proceeds with an empty message.")
+	fmt.Println("  - The commit-msg hook also checks hand-written messages; git's merge, revert and")
+	fmt.Println("    fixup! messages pass.")
+}
+
+// shellQuote quotes s for sh: single quotes keep $, backticks and
+// backslashes literal, and an embedded quote closes the string, is escaped
+// with a backslash and reopens it.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
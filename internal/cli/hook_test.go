@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	for _, path := range []string{
+		"/usr/local/bin/gessage",
+		"/home/me/My Tools/gessage",
+		"/tmp/$HOME/`id`/gessage",
+		`/tmp/back\slash/"quoted"/gessage`,
+		"/tmp/it's/gessage",
+	} {
+		out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(path)).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", path, err)
+		}
+		if string(out) != path {
+			t.Errorf("shellQuote(%q) round-tripped to %q", path, out)
+		}
+	}
+}
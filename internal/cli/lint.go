@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// lintSource is one message to check and where it came from.
+type lintSource struct {
+	name string // file path, "<stdin>" or short commit hash
+	msg  string
+}
+
+func (a *App) runLint(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage lint", flag.ContinueOnError)
+	fs.Usage = printLintUsage
+	var (
+		flagRange = fs.String("range", "", "Lint the messages of the commits in a range, e.g. origin/main..HEAD")
+		flagFile  = fs.String("file", "", "Lint the message in a file (e.g. .git/COMMIT_EDITMSG)")
+		flagFix   = fs.Bool("fix", false, "Rewrite the message through the normalizer (file or stdin only)")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	stdin := fs.NArg() == 1 && fs.Arg(0) == "-"
+	inputs := 0
+	for _, set := range []bool{*flagRange != "", *flagFile != "", stdin} {
+		if set {
+			inputs++
+		}
+	}
+	if inputs != 1 || (fs.NArg() > 0 && !stdin) {
+		printLintUsage()
+		return errors.New("expected exactly one of --range, --file or -")
+	}
+	if *flagFix && *flagRange != "" {
+		return errors.New("--fix cannot rewrite commits; use: gessage reword " + *flagRange)
+	}
+	opts := commitNormalizeOptions("", nil, nil)
+
+	// Step 1: Collect the messages
+	var sources []lintSource
+	switch {
+	case *flagRange != "":
+		if _, err := git.Discover(ctx); err != nil {
+			return err
+		}
+		args := []string{"--no-merges", *flagRange}
+		if !strings.Contains(*flagRange, "..") {
+			args = []string{"-1", *flagRange}
+		}
+		commits, err := git.Log(ctx, args...)
+		if err != nil {
+			return err
+		}
+		for _, c := range commits {
+			sources = append(sources, lintSource{name: c.Hash[:7], msg: c.Message})
+		}
+	case *flagFile != "":
+		b, err := os.ReadFile(*flagFile)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, lintSource{name: *flagFile, msg: string(b)})
+	default:
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, lintSource{name: "<stdin>", msg: string(b)})
+	}
+
+	// Step 2: Fix in place (file) or print the fixed message (stdin). A
+	// message that already passes is left alone: the normalizer is lossy.
+	if *flagFix {
+		src := sources[0]
+		fixed := strings.TrimSpace(src.msg)
+		if len(format.LintMessage(src.msg, opts)) > 0 {
+			fixed = format.FixMessage(src.msg, opts)
+		}
+		if stdin {
+			fmt.Println(fixed)
+			return nil
+		}
+		if fixed != strings.TrimSpace(src.msg) {
+			if err := os.WriteFile(src.name, []byte(fixed+"\n"), 0o644); err != nil {
+				return err
+			}
+			color.Green("Fixed %s", src.name)
+		}
+		sources[0].msg = fixed
+	}
+
+	// Step 3: Report violations as <source>:<line>:<column>
+	problems, failed := 0, 0
+	for _, src := range sources {
+		violations := format.LintMessage(src.msg, opts)
+		if len(violations) == 0 {
+			continue
+		}
+		failed++
+		problems += len(violations)
+		for _, v := range violations {
+			fmt.Printf("%s:%s\n", src.name, v)
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) in %d of %d message(s)", problems, failed, len(sources))
+	}
+	if *flagRange != "" {
+		color.Green("%d message(s) OK", len(sources))
+	}
+	return nil
+}
+
+func printLintUsage() {
+	fmt.Println("gessage lint - check commit messages against the Conventional Commit rules")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage lint --range <A..B>")
+	fmt.Println("  gessage lint [--fix] --file <path>")
+	fmt.Println("  gessage lint [--fix] -")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --range string     Lint every non-merge commit in a range (a single revision lints that commit)")
+	fmt.Println("  --file string      Lint the message in a file")
+	fmt.Println("  --fix              Rewrite the message through the normalizer; with - it is printed")
+	fmt.Println()
+	fmt.Println("Rules:")
+	fmt.Println("  title-format, type-enum, title-max-length (72), body-leading-blank,")
+	fmt.Println("  body-max-line-length (100; the trailer block is exempt)")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - Violations are printed as <source>:<line>:<column>; the exit status is 1 on failure.")
+	fmt.Println("  - '#' comment lines and the 'git commit --verbose' diff are ignored.")
+	fmt.Println("  - Merge, revert and fixup!/squash!/amend! messages written by git pass.")
+	fmt.Println("  - 'gessage hook install --commit-msg' runs 'lint --file' on every commit.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage lint --range origin/main..HEAD")
+	fmt.Println("  git log -1 --format=%B | gessage lint -")
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		fix     bool
+		msg     string
+		want    string // file content afterwards
+		wantErr string
+	}{
+		{name: "passing", msg: "feat: add login\n", want: "feat: add login\n"},
+		{name: "breaking", msg: "feat(api)!: drop v1\n", want: "feat(api)!: drop v1\n"},
+		{name: "failing", msg: "add login\n", want: "add login\n", wantErr: "1 problem(s) in 1 of 1 message(s)"},
+		{name: "fix passing is a no-op", fix: true, msg: "# comment\nfeat: add login\n", want: "# comment\nfeat: add login\n"},
+		{name: "fix breaking is a no-op", fix: true, msg: "feat(api)!: drop v1\n", want: "feat(api)!: drop v1\n"},
+		{name: "fix missing type", fix: true, msg: "add login\n", want: "chore: add login\n"},
+		{name: "fix blank line", fix: true, msg: "fix: typo\nin readme\n", want: "fix: typo\n\nin readme\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+			if err := os.WriteFile(path, []byte(tt.msg), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			args := []string{"--file", path}
+			if tt.fix {
+				args = append(args, "--fix")
+			}
+			var err error
+			withStdio(t, "", func() { err = NewApp().runLint(context.Background(), args) })
+			if gotErr := errString(err); gotErr != tt.wantErr {
+				t.Errorf("runLint(%q) error = %q, want %q", tt.msg, gotErr, tt.wantErr)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("runLint(%q) left %q, want %q", tt.msg, b, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintStdinFix(t *testing.T) {
+	for in, want := range map[string]string{
+		"feat(api)!: drop v1\n": "feat(api)!: drop v1\n",
+		"feat: add login\n":     "feat: add login\n",
+		"add login\n":           "chore: add login\n",
+	} {
+		var err error
+		got := withStdio(t, in, func() { err = NewApp().runLint(context.Background(), []string{"--fix", "-"}) })
+		if err != nil {
+			t.Errorf("lint --fix - <<< %q: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("lint --fix - <<< %q printed %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLintRange(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	commitFile(t, "b.txt", "b\n", "feat(api)!: drop v1")
+	gitT(t, "tag", "base")
+	commitFile(t, "c.txt", "c\n", "add c")
+	commitFile(t, "d.txt", "d\n", "fix: d\nwithout a blank line")
+
+	var err error
+	out := withStdio(t, "", func() { err = NewApp().runLint(context.Background(), []string{"--range", "base..HEAD"}) })
+	if got, want := errString(err), "2 problem(s) in 2 of 2 message(s)"; got != want {
+		t.Errorf("lint --range error = %q, want %q", got, want)
+	}
+	for _, rule := range []string{":1:1: ", "[title-format]", ":2:1: ", "[body-leading-blank]"} {
+		if !strings.Contains(out, rule) {
+			t.Errorf("lint --range output %q does not contain %q", out, rule)
+		}
+	}
+
+	withStdio(t, "", func() { err = NewApp().runLint(context.Background(), []string{"--range", "HEAD~2"}) })
+	if err != nil {
+		t.Errorf("lint --range HEAD~2 (a single breaking commit): %v", err)
+	}
+	if err := NewApp().runLint(context.Background(), []string{"--fix", "--range", "base..HEAD"}); err == nil {
+		t.Error("lint --fix --range succeeded, want an error")
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/diff"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/sanitize"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+// Limits shared by every command that produces a commit message.
+const (
+	maxTitle = 72
+	maxBody  = 100
+)
+
+// prepareDiff expands submodule pointer bumps into their commit subjects,
+// redacts secrets from every hunk and drops whole files (then hunks) beyond
+// maxBytes so that the diff is safe to hand to an AI provider. It also
+// returns how many secrets were redacted.
+func prepareDiff(ctx context.Context, d diff.Diff, maxBytes int) (diff.Diff, int) {
+	d = git.DescribeSubmodules(ctx, d)
+	safe := diff.Diff{Omitted: d.Omitted}
+	redacted := 0
+	for _, f := range d.Files {
+		if f.Submodule {
+			// The header lists the submodule's commit subjects
+			var stats sanitize.Stats
+			f.Header, stats = sanitize.Redact(f.Header)
+			redacted += stats.RedactedCount
+		}
+		hunks := make([]diff.Hunk, len(f.Hunks))
+		for i, h := range f.Hunks {
+			var stats sanitize.Stats
+			h.Body, stats = sanitize.Redact(h.Body)
+			redacted += stats.RedactedCount
+			hunks[i] = h
+		}
+		f.Hunks = hunks
+		safe.Files = append(safe.Files, f)
+	}
+	return safe.Truncate(maxBytes), redacted
+}
+
+// redact removes secrets from text bound for an AI provider.
+func redact(s string) string {
+	safe, _ := sanitize.Redact(s)
+	return safe
+}
+
+// generator bundles the client and limits shared by commands that generate
+// several messages in one run.
+type generator struct {
+	client    ai.Client // nil when no model is available
+	cfg       *config.Config
+	name      string // model name the client was built for
+	maxTokens int
+	maxBytes  int
+	style     *format.StyleProfile // learned commit style, nil when none
+}
+
+// resolveModelName picks the model from the flag, the persisted default and
+// (optionally) the size-based selector, in that order.
+func resolveModelName(cfg *config.Config, requested string, auto bool, diffBytes int) (string, error) {
+	modelName := requested
+	if modelName == "" {
+		modelName = cfg.SelectedModel
+	}
+	if auto {
+		modelName = ai.AutoSelectModelName(modelName, diffBytes) // selector may override based on diff size
+	}
+	if modelName == "" {
+		return "", errors.New("no model selected")
+	}
+	return modelName, nil
+}
+
+// newClient builds the AI client for modelName from its persisted config.
+func newClient(cfg *config.Config, modelName string) (ai.Client, error) {
+	if err := registerInstance(cfg, modelName); err != nil {
+		return nil, fmt.Errorf("create model: %w", err)
+	}
+	client, err := ai.Create(modelName, cfg.Models[modelName])
+	if err != nil {
+		return nil, fmt.Errorf("create model: %w", err)
+	}
+	return client, nil
+}
+
+// registerInstance makes a named instance (a model whose config has a
+// "provider" key) known to the ai registry under its own name.
+func registerInstance(cfg *config.Config, modelName string) error {
+	base := cfg.Models[modelName]["provider"]
+	if base == "" || base == modelName {
+		return nil
+	}
+	if err := ai.RegisterInstance(modelName, base); err != nil {
+		return fmt.Errorf("%w; known: %v", err, ai.Known())
+	}
+	return nil
+}
+
+// registerInstances registers every named instance in cfg so model pickers
+// list them; broken ones are reported and left out.
+func registerInstances(cfg *config.Config) {
+	names := make([]string, 0, len(cfg.Models))
+	for name := range cfg.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := registerInstance(cfg, name); err != nil {
+			color.Yellow("Ignoring %v", err)
+		}
+	}
+}
+
+// errAborted is returned by generateLive when Ctrl-C stopped a stream.
+var errAborted = errors.New("generation aborted")
+
+// generateLive asks client for a message behind a spinner. With live set and a
+// client that streams, tokens are printed as they arrive instead, and Ctrl-C
+// aborts the request (errAborted) rather than the program.
+func generateLive(ctx context.Context, client ai.Client, label, prompt string, maxTokens int, live bool) (string, error) {
+	sc, ok := client.(ai.StreamingClient)
+	spin := ui.NewSpinner(label)
+	spin.Start()
+	if !live || !ok {
+		msg, err := client.Generate(ctx, prompt, maxTokens)
+		spin.Stop()
+		return msg, err
+	}
+
+	streamCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	dim := color.New(color.FgHiBlack)
+	started := false
+	msg, err := sc.GenerateStream(streamCtx, prompt, maxTokens, func(token string) {
+		if !started {
+			spin.Stop()
+			started = true
+		}
+		dim.Print(token)
+	})
+	spin.Stop() // the caller's newline ends the streamed text
+	if streamCtx.Err() != nil && ctx.Err() == nil {
+		return msg, errAborted
+	}
+	return msg, err
+}
+
+// genErrorHint says what to do about a failed generation when the provider
+// reported why, or returns "" for other errors.
+func genErrorHint(modelName string, err error) string {
+	var pe *ai.ProviderError
+	if !errors.As(err, &pe) {
+		return ""
+	}
+	switch {
+	case errors.Is(err, ai.ErrAuth):
+		return fmt.Sprintf("The credentials for %s were rejected. Run 'gessage setup --model %s' to update them.", modelName, modelName)
+	case errors.Is(err, ai.ErrRateLimited) && pe.Code == "insufficient_quota":
+		return fmt.Sprintf("The account behind %s is out of quota. Check its billing, or use another model with --model.", modelName)
+	case errors.Is(err, ai.ErrRateLimited):
+		wait := ""
+		if pe.RetryAfter > 0 {
+			wait = fmt.Sprintf(" (it asked to wait %s)", pe.RetryAfter.Round(time.Second))
+		}
+		return fmt.Sprintf("%s is rate limiting requests%s. Try again later, or use another model with --model.", modelName, wait)
+	case errors.Is(err, ai.ErrContextLength):
+		return "The diff is too large for this model. Lower --max-bytes, commit fewer files at a time, or try 'gessage split'."
+	case errors.Is(err, ai.ErrServer):
+		return fmt.Sprintf("%s kept failing on the server side. Try again shortly, or use another model with --model.", modelName)
+	case errors.Is(err, ai.ErrModelNotFound):
+		return fmt.Sprintf("The model configured for %s is not available. Pick another with 'gessage default --model %s'.", modelName, modelName)
+	}
+	return ""
+}
+
+// printGenErrorHint prints genErrorHint, if any, below a failure warning.
+func printGenErrorHint(modelName string, err error) {
+	if hint := genErrorHint(modelName, err); hint != "" {
+		color.Yellow("Hint: %s", hint)
+	}
+}
+
+// commitNormalizeOptions returns the Conventional Commit constraints used
+// throughout the CLI, falling back to defaultType when the model omits one.
+// A learned style (may be nil) adjusts the title for a change to paths.
+func commitNormalizeOptions(defaultType string, style *format.StyleProfile, paths []string) format.NormalizeOptions {
+	if defaultType == "" {
+		defaultType = "chore"
+	}
+	return format.NormalizeOptions{
+		MaxTitle:    maxTitle,
+		MaxBody:     maxBody,
+		Types:       format.AllowedTypes,
+		DefaultType: defaultType,
+		Style:       style,
+		Paths:       paths,
+	}
+}
+
+// branchIssue extracts the issue key from the current branch name using the
+// repository's issue patterns. It returns the key for the prompt and the
+// trailers to append after normalization; problems are reported, not fatal.
+func branchIssue(ctx context.Context) (key string, trailers []string) {
+	branch := git.CurrentBranch(ctx)
+	if branch == "" {
+		return "", nil
+	}
+	top, err := git.TopLevel(ctx)
+	if err != nil {
+		return "", nil
+	}
+	rc, err := config.LoadRepo(top)
+	if err != nil {
+		color.Yellow("Ignoring %s: %v", config.RepoFile, err)
+		return "", nil
+	}
+	key, trailer, ok := rc.IssueFromBranch(branch)
+	if !ok {
+		return "", nil
+	}
+	return key, []string{trailer}
+}
+
+// commitTrailers renders --trailer values ("key=value" or "Key: value") and
+// --pair aliases from the config as trailer lines.
+func commitTrailers(cfg *config.Config, trailerFlags, pairs []string) ([]string, error) {
+	var out []string
+	for _, t := range trailerFlags {
+		// Whichever separator comes first ends the key, so values may
+		// contain the other one ("Refs: a=b", "Link=https://...").
+		key, value := t, ""
+		if i := strings.IndexAny(t, "=:"); i >= 0 {
+			key, value = t[:i], t[i+1:]
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" || value == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid --trailer %q; use key=value", t)
+		}
+		out = append(out, key+": "+value)
+	}
+	for _, alias := range pairs {
+		for _, a := range strings.Split(alias, ",") {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				continue
+			}
+			who, ok := cfg.Pairs[a]
+			if !ok {
+				known := make([]string, 0, len(cfg.Pairs))
+				for k := range cfg.Pairs {
+					known = append(known, k)
+				}
+				sort.Strings(known)
+				return nil, fmt.Errorf("unknown pair %q; add it under \"pairs\" in the config file (known: %v)", a, known)
+			}
+			out = append(out, "Co-authored-by: "+who)
+		}
+	}
+	return out, nil
+}
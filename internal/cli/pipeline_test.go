@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/ai"
+	"github.com/ispooya/gessage-cli/internal/config"
+)
+
+func TestCommitTrailers(t *testing.T) {
+	cfg := &config.Config{Pairs: map[string]string{
+		"ana": "Ana Lima <ana@example.com>",
+		"bo":  "Bo Chen <bo@example.com>",
+	}}
+	tests := []struct {
+		name     string
+		trailers []string
+		pairs    []string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "key=value", trailers: []string{"Reviewed-by=Ana"}, want: []string{"Reviewed-by: Ana"}},
+		{name: "key: value", trailers: []string{"Refs: PAY-1"}, want: []string{"Refs: PAY-1"}},
+		{name: "colon before equals", trailers: []string{"Refs: a=b"}, want: []string{"Refs: a=b"}},
+		{name: "equals before colon", trailers: []string{"Link=https://example.com/x"}, want: []string{"Link: https://example.com/x"}},
+		{name: "pairs", pairs: []string{"ana, bo", "ana"}, want: []string{
+			"Co-authored-by: Ana Lima <ana@example.com>",
+			"Co-authored-by: Bo Chen <bo@example.com>",
+			"Co-authored-by: Ana Lima <ana@example.com>",
+		}},
+		{name: "no separator", trailers: []string{"Refs"}, wantErr: true},
+		{name: "empty value", trailers: []string{"Refs="}, wantErr: true},
+		{name: "space in key", trailers: []string{"Signed off: me"}, wantErr: true},
+		{name: "unknown pair", pairs: []string{"cy"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commitTrailers(cfg, tt.trailers, tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commitTrailers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commitTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterInstance(t *testing.T) {
+	ai.Register("test-base", ai.Provider{})
+	cfg := &config.Config{Models: map[string]map[string]string{
+		"team-llm": {"provider": "test-base"},
+		"typo":     {"provider": "test-bsae"},
+		"plain":    {"model": "x"},
+	}}
+	if err := registerInstance(cfg, "team-llm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ai.ProviderFor("team-llm"); !ok {
+		t.Error("team-llm was not registered")
+	}
+	if err := registerInstance(cfg, "plain"); err != nil {
+		t.Errorf("registerInstance(plain) = %v", err)
+	}
+	err := registerInstance(cfg, "typo")
+	if err == nil || !strings.Contains(err.Error(), `unknown provider "test-bsae"`) {
+		t.Errorf("registerInstance(typo) = %v", err)
+	}
+	if _, ok := ai.ProviderFor("typo"); ok {
+		t.Error("typo was registered")
+	}
+
+	// The same name later configured for another provider in this process
+	ai.Register("test-other", ai.Provider{})
+	cfg.Models["team-llm"]["provider"] = "test-other"
+	err = registerInstance(cfg, "team-llm")
+	if err == nil || !strings.Contains(err.Error(), `already registered as an instance of "test-base"`) {
+		t.Errorf("registerInstance(team-llm) with a new provider = %v", err)
+	}
+}
+
+// stallingStream sends one token, runs then and waits for the stream to be
+// cancelled.
+type stallingStream struct {
+	fakeClient
+	then func()
+}
+
+func (s *stallingStream) GenerateStream(ctx context.Context, _ string, _ int, onToken func(string)) (string, error) {
+	onToken("feat")
+	s.then()
+	<-ctx.Done()
+	return "feat", ctx.Err()
+}
+
+func TestGenerateLiveInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send os.Interrupt to itself on Windows")
+	}
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &stallingStream{then: func() { self.Signal(os.Interrupt) }}
+	var msg string
+	withStdio(t, "", func() {
+		msg, err = generateLive(context.Background(), client, "Generating...", "p", 64, true)
+	})
+	if !errors.Is(err, errAborted) || msg != "feat" {
+		t.Errorf("generateLive() after Ctrl-C = %q, %v, want the partial text and errAborted", msg, err)
+	}
+}
+
+func TestGenerateLiveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &stallingStream{then: cancel}
+	var (
+		msg string
+		err error
+	)
+	withStdio(t, "", func() {
+		msg, err = generateLive(ctx, client, "Generating...", "p", 64, true)
+	})
+	if errors.Is(err, errAborted) || !errors.Is(err, context.Canceled) || msg != "feat" {
+		t.Errorf("generateLive() with the caller's context cancelled = %q, %v, want context.Canceled", msg, err)
+	}
+}
+
+func TestGenerateLiveNotLive(t *testing.T) {
+	client := &stallingStream{fakeClient: fakeClient{replies: []string{"fix: typo"}}, then: func() { t.Error("streamed without live") }}
+	var (
+		msg string
+		err error
+	)
+	withStdio(t, "", func() {
+		msg, err = generateLive(context.Background(), client, "Generating...", "the prompt", 64, false)
+	})
+	if err != nil || msg != "fix: typo" || len(client.prompts) != 1 || client.prompts[0] != "the prompt" {
+		t.Errorf("generateLive(live=false) = %q, %v with prompts %q", msg, err, client.prompts)
+	}
+}
+
+func TestSubmoduleSubjectsInPrompt(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	lib := filepath.Join(t.TempDir(), "lib")
+	gitT(t, "init", "-q", "-b", "main", lib)
+	gitT(t, "-C", lib, "commit", "-q", "--allow-empty", "-m", "feat: one")
+	gitT(t, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	gitT(t, "commit", "-q", "-m", "chore: add lib")
+	gitT(t, "-C", "lib", "commit", "-q", "--allow-empty", "-m", "feat: add retries")
+	gitT(t, "-C", "lib", "commit", "-q", "--allow-empty", "-m", "fix: rotate password=correcthorsebattery")
+	gitT(t, "add", "lib")
+	useFakeModel(t, "chore: bump lib")
+
+	var err error
+	out := withStdio(t, "", func() { err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "  > feat: add retries\n") {
+		t.Errorf("prompt does not list the submodule's new commits:\n%s", out)
+	}
+	if strings.Contains(out, "correcthorsebattery") || !strings.Contains(out, "  > fix: rotate [REDACTED]\n") {
+		t.Errorf("prompt does not redact the submodule's commit subjects:\n%s", out)
+	}
+}
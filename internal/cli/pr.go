@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+// prTemplatePaths are checked, relative to the repository root, for a pull
+// request template whose headings the generated body should follow.
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"pull_request_template.md",
+}
+
+func (a *App) runPR(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage pr", flag.ContinueOnError)
+	fs.Usage = printPRUsage
+	var (
+		flagBase      = fs.String("base", "", "Base branch to compare against (default: the remote's default branch)")
+		flagRemote    = fs.String("remote", "origin", "Remote whose default branch is used when --base is not set")
+		flagModel     = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
+		flagAuto      = fs.Bool("auto", true, "Auto-select model based on diff size (overrides --model if needed)")
+		flagMaxTokens = fs.Int("max-tokens", 1024, "Max tokens for AI generation")
+		flagDryRun    = fs.Bool("dry-run", false, "Print sanitized diff and prompt; do not call AI")
+		flagMaxBytes  = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI (after sanitization)")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	// Step 1: Find the merge-base with the base branch
+	base := strings.TrimSpace(*flagBase)
+	if base == "" {
+		var err error
+		if base, err = git.DefaultBranch(ctx, *flagRemote); err != nil {
+			return err
+		}
+	}
+	mergeBase, err := git.MergeBase(ctx, base, "HEAD")
+	if err != nil {
+		return fmt.Errorf("no common history with %s: %w", base, err)
+	}
+
+	// Step 2: Collect commit subjects and the combined diff
+	commits, err := git.Log(ctx, "--reverse", mergeBase+"..HEAD")
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("HEAD has no commits on top of %s", base)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, redact(c.Subject())) // redacted like the diff
+	}
+	diff, err := git.DiffRevs(ctx, mergeBase, "HEAD")
+	if err != nil {
+		return err
+	}
+	safe, _ := prepareDiff(ctx, diff, *flagMaxBytes)
+
+	// Step 3: Follow the repository's PR template when there is one
+	sections := format.DefaultPRSections
+	if tmpl, path := readPRTemplate(ctx); tmpl != "" {
+		if found := format.TemplateSections(tmpl); len(found) > 0 {
+			color.Cyan("Using PR template: %s", path)
+			sections = found
+		}
+	}
+
+	prompt := format.BuildPRPrompt(format.PRPromptInput{
+		Diff:     safe,
+		Commits:  subjects,
+		Sections: sections,
+		MaxTitle: maxTitle,
+	})
+	if *flagDryRun {
+		fmt.Println("=== [SANITIZED DIFF] ===")
+		fmt.Println(safe)
+		fmt.Println("\n=== [PROMPT] ===")
+		fmt.Println(prompt)
+		return nil
+	}
+
+	// Step 4: Generate via the configured client
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	modelName, err := resolveModelName(cfg, *flagModel, *flagAuto, len(safe.String()))
+	if err != nil {
+		color.Yellow("No model configured. Run: gessage setup")
+		return err
+	}
+	color.Cyan("Using model: %s", modelName)
+	client, err := newClient(cfg, modelName)
+	if err != nil {
+		return err
+	}
+
+	spin := ui.NewSpinner("Generating pull request description...")
+	spin.Start()
+	out, genErr := client.Generate(ctx, prompt, *flagMaxTokens)
+	spin.Stop()
+	fmt.Println()
+
+	var title, body string
+	if genErr == nil {
+		title, body = format.ParsePRResponse(out)
+	}
+	if title == "" || body == "" {
+		color.Yellow("AI failed or returned an incomplete description. Falling back. err=%v", genErr)
+		printGenErrorHint(modelName, genErr)
+		title, body = format.FallbackPR(subjects, sections)
+	}
+	title = format.TruncateTitle(title, maxTitle)
+
+	fmt.Println(title)
+	fmt.Println()
+	fmt.Println(body)
+	return nil
+}
+
+// readPRTemplate returns the first pull request template found in the repository.
+func readPRTemplate(ctx context.Context) (content, path string) {
+	top, err := git.TopLevel(ctx)
+	if err != nil {
+		return "", ""
+	}
+	for _, rel := range prTemplatePaths {
+		p := filepath.Join(top, rel)
+		b, err := os.ReadFile(p)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				color.Yellow("Cannot read %s: %v", p, err)
+			}
+			continue
+		}
+		return string(b), rel
+	}
+	return "", ""
+}
+
+func printPRUsage() {
+	fmt.Println("gessage pr - generate a pull request title and description for the current branch")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage pr [--base <branch>] [flags]")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --base string      Base branch to compare against (default: the remote's default branch)")
+	fmt.Println("  --remote string    Remote used to find the default branch (default origin)")
+	fmt.Println("  --model string     AI model to use (e.g., gpt4-o, openrouter, ollama)")
+	fmt.Println("  --auto             Auto-select model based on diff size (default true)")
+	fmt.Println("  --max-tokens int   Max tokens for AI generation (default 1024)")
+	fmt.Println("  --dry-run          Print sanitized diff and prompt; do not call AI")
+	fmt.Println("  --max-bytes int    Max diff bytes to send to AI after sanitization (default 100000)")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - The first line printed is the title; the rest is the Markdown body.")
+	fmt.Println("  - The body follows the headings of .github/pull_request_template.md when present,")
+	fmt.Println("    otherwise it uses Summary, Changes and Testing sections.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage pr")
+	fmt.Println("  gessage pr --base develop")
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPRPromptRedactsCommits(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "checkout", "-q", "-b", "topic")
+	commitFile(t, "b.txt", "b\n", "fix: use password=correct-horse-battery")
+	writeFile(t, ".github/pull_request_template.md", "## What\n\n## Risk\n")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"pr", "--base", "main", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, prompt, _ := strings.Cut(out, "=== [PROMPT] ===")
+	if strings.Contains(prompt, "correct-horse-battery") {
+		t.Errorf("pr prompt leaks a secret from a commit subject:\n%s", prompt)
+	}
+	for _, want := range []string{"fix: use [REDACTED]", "What", "Risk"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("pr prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
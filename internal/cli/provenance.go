@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// provenance records how a commit message was produced. It is stored as a
+// git note under git.NotesRef when enabled with --provenance or the config.
+type provenance struct {
+	Provider      string // registry name, e.g. gpt4-o
+	Model         string // provider's model identifier
+	PromptSHA256  string
+	Redactions    int // secrets removed from the diff before it was sent
+	Regenerations int
+	Edited        bool
+	Fallback      bool // the model failed and the message was built from the diff
+}
+
+func newProvenance(cfg *config.Config, modelName, prompt string, redactions int) provenance {
+	model := cfg.Models[modelName]["model"]
+	if model == "" {
+		model = "(provider default)"
+	}
+	sum := sha256.Sum256([]byte(prompt))
+	return provenance{
+		Provider:     modelName,
+		Model:        model,
+		PromptSHA256: hex.EncodeToString(sum[:]),
+		Redactions:   redactions,
+	}
+}
+
+// String renders the note as "key: value" lines.
+func (p provenance) String() string {
+	return "generator: gessage " + Version + "\n" +
+		"provider: " + p.Provider + "\n" +
+		"model: " + p.Model + "\n" +
+		"prompt-sha256: " + p.PromptSHA256 + "\n" +
+		"redactions: " + strconv.Itoa(p.Redactions) + "\n" +
+		"regenerations: " + strconv.Itoa(p.Regenerations) + "\n" +
+		"edited: " + strconv.FormatBool(p.Edited) + "\n" +
+		"fallback: " + strconv.FormatBool(p.Fallback) + "\n"
+}
+
+// recordProvenance attaches p to rev. The commit already exists at this
+// point, so a failure is reported but not returned.
+func recordProvenance(ctx context.Context, rev string, p provenance) {
+	if err := git.AddNote(ctx, git.NotesRef, rev, p.String()); err != nil {
+		color.Yellow("Could not record provenance for %s: %v", rev, err)
+	}
+}
+
+func (a *App) runProvenance(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage provenance", flag.ContinueOnError)
+	fs.Usage = printProvenanceUsage
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 1 {
+		printProvenanceUsage()
+		return errors.New("expected at most one revision")
+	}
+	rev := "HEAD"
+	if fs.NArg() == 1 {
+		rev = fs.Arg(0)
+	}
+	id, err := git.ResolveRev(ctx, rev)
+	if err != nil {
+		return err
+	}
+	note, err := git.Note(ctx, git.NotesRef, id)
+	if err != nil {
+		return err
+	}
+	if note == "" {
+		return fmt.Errorf("no provenance recorded for %s (fetch %s if it was recorded elsewhere)", id[:7], git.NotesRef)
+	}
+	color.Cyan("commit %s", id)
+	fmt.Println(note)
+	return nil
+}
+
+func printProvenanceUsage() {
+	fmt.Println("gessage provenance - show how a commit message was generated")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage provenance [<rev>]")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - Provenance is recorded with --provenance or \"provenance\": true in the config.")
+	fmt.Println("  - It is stored as a git note under " + git.NotesRef + "; see the README to share it.")
+	fmt.Println("  - The prompt itself is not stored, only its SHA-256.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage provenance")
+	fmt.Println("  gessage provenance HEAD~3")
+	fmt.Println("  git log --notes=gessage")
+}
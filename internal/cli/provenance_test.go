@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+func TestRecordProvenance(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		args  []string
+		note  string // expected note, minus the generator and prompt hash lines
+	}{
+		{
+			name:  "approved",
+			input: "a\n",
+			args:  []string{"--provenance"},
+			note:  "provider: fake\nmodel: (provider default)\nredactions: 1\nregenerations: 0\nedited: false\nfallback: false",
+		},
+		{
+			name:  "regenerated",
+			input: "r\na\n",
+			args:  []string{"--provenance"},
+			note:  "provider: fake\nmodel: (provider default)\nredactions: 1\nregenerations: 1\nedited: false\nfallback: false",
+		},
+		{name: "not asked for", input: "a\n"},
+	}
+	hash := regexp.MustCompile(`(?m)^prompt-sha256: [0-9a-f]{64}\n`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo(t)
+			commitFile(t, "a.txt", "a\n", "feat: add a")
+			writeFile(t, "config.env", "API_KEY=abcdef0123456789abcdef\n")
+			gitT(t, "add", "config.env")
+
+			out, err := runGessage(t, tt.input, []string{"chore: add config"}, append(tt.args, "--model", "fake")...)
+			if err != nil {
+				t.Fatalf("gessage: %v\n%s", err, out)
+			}
+			note, err := git.Note(context.Background(), git.NotesRef, "HEAD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.note == "" {
+				if note != "" {
+					t.Errorf("note recorded without --provenance:\n%s", note)
+				}
+				return
+			}
+			if !hash.MatchString(note) {
+				t.Errorf("note has no prompt hash:\n%s", note)
+			}
+			rest, ok := strings.CutPrefix(hash.ReplaceAllString(note, ""), "generator: gessage "+Version+"\n")
+			if !ok || rest != tt.note {
+				t.Errorf("note:\n%s\nwant:\n%s", note, tt.note)
+			}
+		})
+	}
+}
+
+func TestRewordProvenance(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "tag", "base")
+	commitFile(t, "b.txt", "b\n", "wip")
+	commitFile(t, "c.txt", "c\n", "feat: add c")
+	gitT(t, "notes", "--ref", git.NotesRef, "add", "-m", "provider: earlier", "HEAD")
+
+	// b is reworded and gets a new note; c is only rebuilt and keeps its own
+	out, err := runGessage(t, "a\ns\n", []string{"feat: add b", "feat: add c file"},
+		"reword", "--provenance", "--model", "fake", "base")
+	if err != nil {
+		t.Fatalf("gessage reword: %v\n%s", err, out)
+	}
+	ctx := context.Background()
+	if note, _ := git.Note(ctx, git.NotesRef, "HEAD~1"); !strings.Contains(note, "provider: fake\n") {
+		t.Errorf("reworded commit's note:\n%s", note)
+	}
+	if note, _ := git.Note(ctx, git.NotesRef, "HEAD"); note != "provider: earlier" {
+		t.Errorf("rebuilt commit's note = %q, want the original one carried over", note)
+	}
+}
+
+func TestProvenanceCommand(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	id := gitT(t, "rev-parse", "HEAD")
+
+	var err error
+	withStdio(t, "", func() { err = NewApp().Run(context.Background(), []string{"provenance"}) })
+	if want := "no provenance recorded for " + id[:7]; !strings.HasPrefix(errString(err), want) {
+		t.Errorf("provenance without a note = %v, want %q", err, want)
+	}
+	gitT(t, "notes", "--ref", git.NotesRef, "add", "-m", "provider: fake", "HEAD")
+	out := withStdio(t, "", func() { err = NewApp().Run(context.Background(), []string{"provenance", "main"}) })
+	if err != nil || !strings.Contains(out, "commit "+id) || !strings.Contains(out, "provider: fake") {
+		t.Errorf("provenance main = %v, printed:\n%s", err, out)
+	}
+}
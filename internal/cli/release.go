@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/changelog"
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/release"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func (a *App) runRelease(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage release", flag.ContinueOnError)
+	fs.Usage = printReleaseUsage
+	var (
+		flagTag       = fs.Bool("tag", false, "Create an annotated tag with AI-written release notes")
+		flagDryRun    = fs.Bool("dry-run", false, "Show the version and release notes; do not create the tag")
+		flagPre       = fs.String("pre", "", "Pre-release identifier, e.g. rc produces 1.3.0-rc.N")
+		flagPrefix    = fs.String("tag-prefix", "v", "Tag prefix, e.g. api/v for monorepo components")
+		flagModel     = fs.String("model", "", "AI model to use for release notes (e.g., gpt4-o, openrouter, ollama)")
+		flagMaxTokens = fs.Int("max-tokens", 1024, "Max tokens for AI generation")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	// Step 1: Find the latest stable release tag reachable from HEAD
+	names, err := git.MergedTags(ctx, *flagPrefix+"*", "HEAD")
+	if err != nil {
+		return err
+	}
+	tags := release.ParseTags(names, *flagPrefix)
+	latest, hasLatest := release.LatestStable(tags)
+	revRange := "HEAD"
+	if hasLatest {
+		revRange = latest.Name + "..HEAD"
+	}
+
+	// Step 2: Classify the commits since then
+	commits, err := git.Log(ctx, "--no-merges", revRange)
+	if err != nil {
+		return err
+	}
+	bump := release.Classify(commits)
+	if bump == release.BumpNone {
+		color.Yellow("No feat, fix, perf or breaking commits in %s; nothing to release.", revRange)
+		return nil
+	}
+	next := release.Next(latest.Version, bump)
+	if *flagPre != "" {
+		next = release.NextPrerelease(tags, next, *flagPre)
+	}
+	tagName := *flagPrefix + next.String()
+
+	from := "(none)"
+	if hasLatest {
+		from = latest.Name
+	}
+	color.Cyan("Latest release: %s, %d commit(s) since, %s bump", from, len(commits), bump)
+	fmt.Println(tagName)
+	if !*flagTag {
+		return nil
+	}
+
+	// Step 3: Release notes from the deterministic changelog, rewritten for end users
+	date, err := git.CommitDate(ctx, "HEAD")
+	if err != nil {
+		return err
+	}
+	section := changelog.Render(commits, changelog.Options{Version: next.String(), Date: date})
+	notes := generateReleaseNotes(ctx, tagName, section, *flagModel, *flagMaxTokens)
+
+	if *flagDryRun {
+		fmt.Println("\n=== [RELEASE NOTES] ===")
+		fmt.Println(notes)
+		color.Yellow("\n[DRY-RUN] Tag %s not created.", tagName)
+		return nil
+	}
+	if err := git.CreateAnnotatedTag(ctx, tagName, tagName+"\n\n"+notes+"\n"); err != nil {
+		return err
+	}
+	color.Green("Created tag %s. Push it with: git push origin %s", tagName, tagName)
+	return nil
+}
+
+// generateReleaseNotes asks the configured model for user-facing notes and
+// falls back to the changelog section when no model is available or it fails.
+func generateReleaseNotes(ctx context.Context, tagName, section, requested string, maxTokens int) string {
+	fallback := strings.TrimSpace(section)
+	cfg, err := config.Load()
+	if err != nil {
+		color.Yellow("Cannot load config (%v); using the changelog as release notes.", err)
+		return fallback
+	}
+	modelName, err := resolveModelName(cfg, requested, false, 0)
+	if err != nil {
+		color.Yellow("No model configured; using the changelog as release notes.")
+		return fallback
+	}
+	client, err := newClient(cfg, modelName)
+	if err != nil {
+		color.Yellow("%v; using the changelog as release notes.", err)
+		return fallback
+	}
+	color.Cyan("Using model: %s", modelName)
+
+	prompt := format.BuildReleaseNotesPrompt(format.ReleaseNotesInput{Tag: tagName, Changelog: section})
+	spin := ui.NewSpinner("Writing release notes...")
+	spin.Start()
+	notes, genErr := client.Generate(ctx, prompt, maxTokens)
+	spin.Stop()
+	if genErr != nil || strings.TrimSpace(notes) == "" {
+		color.Yellow("AI failed or returned empty notes. Falling back to the changelog. err=%v", genErr)
+		printGenErrorHint(modelName, genErr)
+		return fallback
+	}
+	return strings.TrimSpace(notes)
+}
+
+func printReleaseUsage() {
+	fmt.Println("gessage release - compute the next semantic version and optionally tag it")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage release [--tag [--dry-run]] [--pre <id>] [--tag-prefix <prefix>]")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --tag                Create an annotated tag with AI-written release notes")
+	fmt.Println("  --dry-run            Show the version and release notes; do not create the tag")
+	fmt.Println("  --pre string         Pre-release identifier, e.g. rc produces 1.3.0-rc.N")
+	fmt.Println("  --tag-prefix string  Tag prefix, e.g. api/v for monorepo components (default v)")
+	fmt.Println("  --model string       AI model to use for release notes")
+	fmt.Println("  --max-tokens int     Max tokens for AI generation (default 1024)")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - Commits since the latest stable tag decide the bump: breaking -> major, feat -> minor, fix/perf -> patch.")
+	fmt.Println("  - Without --tag only the next version is printed.")
+	fmt.Println("  - Pre-release numbers continue from existing tags (1.3.0-rc.1, 1.3.0-rc.2, ...).")
+	fmt.Println("  - If no model is configured or it fails, the changelog section is used as release notes.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage release")
+	fmt.Println("  gessage release --tag --dry-run")
+	fmt.Println("  gessage release --tag --pre rc")
+	fmt.Println("  gessage release --tag --tag-prefix api/v")
+}
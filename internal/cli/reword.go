@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+// backupRefPrefix is where history-rewriting commands record the previous HEAD.
+const backupRefPrefix = "refs/gessage/backup/"
+
+func (a *App) runReword(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage reword", flag.ContinueOnError)
+	fs.Usage = printRewordUsage
+	var (
+		flagModel      = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
+		flagMaxTokens  = fs.Int("max-tokens", 512, "Max tokens for AI generation")
+		flagMaxBytes   = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI per commit (after sanitization)")
+		flagProvenance = fs.Bool("provenance", false, "Record how each new message was generated in a git note ("+git.NotesRef+")")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 1 {
+		printRewordUsage()
+		return errors.New("expected exactly one commit range, e.g. origin/main..HEAD")
+	}
+
+	// Step 1: Resolve the range; it must end at HEAD so the rewrite is a plain ref move
+	revRange := fs.Arg(0)
+	if strings.Contains(revRange, "...") {
+		return errors.New("symmetric ranges (A...B) are not supported; use A..B")
+	}
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	_, end, _ := strings.Cut(revRange, "..")
+	if end == "" {
+		end = "HEAD"
+	}
+	head, err := git.ResolveRev(ctx, "HEAD")
+	if err != nil {
+		return err
+	}
+	endID, err := git.ResolveRev(ctx, end)
+	if err != nil {
+		return err
+	}
+	if endID != head {
+		return fmt.Errorf("range must end at HEAD; check out %s first", end)
+	}
+
+	commits, err := git.Log(ctx, "--reverse", "--topo-order", revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", revRange)
+	}
+
+	// Step 2: Refuse merges and anything already published upstream
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return fmt.Errorf("commit %s is a merge; reword only supports linear history", c.Hash[:7])
+		}
+	}
+	if upstream := git.Upstream(ctx); upstream != "" {
+		for _, c := range commits {
+			published, err := git.IsAncestor(ctx, c.Hash, upstream)
+			if err != nil {
+				return err
+			}
+			if published {
+				return fmt.Errorf("commit %s is already on %s; refusing to rewrite published history", c.Hash[:7], upstream)
+			}
+		}
+	}
+
+	// Step 3: Wire the model once for the whole range
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	modelName, err := resolveModelName(cfg, *flagModel, false, 0)
+	if err != nil {
+		color.Yellow("No model configured. Run: gessage setup")
+		return err
+	}
+	color.Cyan("Using model: %s", modelName)
+	client, err := newClient(cfg, modelName)
+	if err != nil {
+		return err
+	}
+	gen := generator{client: client, cfg: cfg, name: modelName, maxTokens: *flagMaxTokens, maxBytes: *flagMaxBytes, style: repoStyle(ctx)}
+
+	// Step 4: Propose a message per commit and collect approvals
+	messages := map[string]string{}
+	provs := map[string]provenance{}
+	for i, c := range commits {
+		proposal, prov, err := proposeReword(ctx, gen, c)
+		if err != nil {
+			return err
+		}
+		newMsg, prov, keep, err := approveReword(ctx, gen, c, proposal, prov, i+1, len(commits))
+		if err != nil {
+			return err
+		}
+		if keep && newMsg != c.Message {
+			messages[c.Hash] = newMsg
+			provs[c.Hash] = prov
+		}
+	}
+	if len(messages) == 0 {
+		color.Yellow("No messages changed; history left untouched.")
+		return nil
+	}
+
+	// Step 5: Back up HEAD, then rebuild the range with commit-tree (trees are unchanged)
+	backup := backupRefPrefix + time.Now().Format("20060102-150405")
+	if err := git.UpdateRef(ctx, backup, head, "", "gessage reword: backup"); err != nil {
+		return err
+	}
+	color.Cyan("Backup of the previous HEAD saved as %s", backup)
+
+	rewritten := map[string]string{}
+	newHead := head
+	for _, c := range commits {
+		parents := make([]string, len(c.Parents))
+		changed := false
+		for i, p := range c.Parents {
+			parents[i] = p
+			if np, ok := rewritten[p]; ok {
+				parents[i] = np
+				changed = true
+			}
+		}
+		msg, reword := messages[c.Hash]
+		if !reword && !changed {
+			newHead = c.Hash
+			continue
+		}
+		if !reword {
+			msg = c.Message
+		}
+		id, err := git.CommitTree(ctx, c, parents, msg+"\n")
+		if err != nil {
+			return fmt.Errorf("%w\nrestore with: git reset --hard %s", err, backup)
+		}
+		rewritten[c.Hash] = id
+		newHead = id
+	}
+	if err := git.UpdateRef(ctx, "HEAD", newHead, head, "gessage reword "+revRange); err != nil {
+		return err
+	}
+	for old, id := range rewritten {
+		if prov, ok := provs[old]; ok {
+			if *flagProvenance || cfg.Provenance {
+				recordProvenance(ctx, id, prov)
+			}
+			continue
+		}
+		// Commits rewritten only because a parent changed keep their note
+		if note, err := git.Note(ctx, git.NotesRef, old); err == nil && note != "" {
+			if err := git.AddNote(ctx, git.NotesRef, id, note+"\n"); err != nil {
+				color.Yellow("Could not carry over the provenance note of %s: %v", old[:7], err)
+			}
+		}
+	}
+	color.Green("Reworded %d commit(s). Undo with: git reset --hard %s", len(messages), backup)
+	return nil
+}
+
+// proposeReword generates a Conventional Commit message for a single commit's own diff.
+func proposeReword(ctx context.Context, gen generator, c git.Commit) (string, provenance, error) {
+	diff, err := git.CommitDiff(ctx, c.Hash)
+	if err != nil {
+		return "", provenance{}, err
+	}
+	if diff.Empty() {
+		return c.Message, provenance{}, nil
+	}
+	safe, redactions := prepareDiff(ctx, diff, gen.maxBytes)
+	prompt := format.BuildPrompt(format.PromptInput{
+		Diff:            safe,
+		Types:           format.AllowedTypes,
+		MaxTitle:        maxTitle,
+		MaxBody:         maxBody,
+		PreviousMessage: redact(c.Message),
+		Style:           gen.style,
+	})
+	prov := newProvenance(gen.cfg, gen.name, prompt, redactions)
+	spin := ui.NewSpinner(fmt.Sprintf("Generating message for %s...", c.Hash[:7]))
+	spin.Start()
+	msg, genErr := gen.client.Generate(ctx, prompt, gen.maxTokens)
+	spin.Stop()
+	if genErr != nil || strings.TrimSpace(msg) == "" {
+		color.Yellow("AI failed for %s; keeping its message. err=%v", c.Hash[:7], genErr)
+		printGenErrorHint(gen.name, genErr)
+		return c.Message, prov, nil
+	}
+	return format.NormalizeMessage(msg, commitNormalizeOptions("", gen.style, diff.Paths())), prov, nil
+}
+
+// approveReword shows old and new messages side by side. It returns the
+// chosen message, its provenance and whether it should replace the original.
+func approveReword(ctx context.Context, gen generator, c git.Commit, proposal string, prov provenance, n, total int) (string, provenance, bool, error) {
+	for {
+		color.White("\n--- Commit %d/%d: %s ---\n", n, total, c.Hash[:7])
+		fmt.Print(ui.SideBySide("Current", c.Message, "Proposed", proposal, ui.TerminalWidth()))
+		fmt.Print("\n[a]pprove  [e]dit  [r]egenerate  [s]kip  [c]ancel > ")
+
+		choice, err := ui.ReadChoice()
+		if err != nil {
+			return "", prov, false, err
+		}
+		switch choice {
+		case "a", "approve":
+			return proposal, prov, true, nil
+		case "e", "edit":
+			edited, err := ui.EditInEditor(proposal)
+			if err != nil {
+				return "", prov, false, err
+			}
+			proposal = format.NormalizeMessage(edited, commitNormalizeOptions("", nil, nil))
+			prov.Edited = true
+		case "r", "regenerate":
+			next, nextProv, err := proposeReword(ctx, gen, c)
+			if err != nil {
+				return "", prov, false, err
+			}
+			nextProv.Regenerations = prov.Regenerations + 1
+			proposal, prov = next, nextProv
+		case "s", "skip":
+			return c.Message, prov, false, nil
+		case "c", "cancel":
+			return "", prov, false, errors.New("cancelled by user; history left untouched")
+		default:
+			color.Yellow("Unknown option: %s", choice)
+		}
+	}
+}
+
+func printRewordUsage() {
+	fmt.Println("gessage reword - rewrite the messages of existing commits as Conventional Commits")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage reword [flags] <range>")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --model string     AI model to use (e.g., gpt4-o, openrouter, ollama)")
+	fmt.Println("  --max-tokens int   Max tokens for AI generation (default 512)")
+	fmt.Println("  --max-bytes int    Max diff bytes to send to AI per commit (default 100000)")
+	fmt.Println("  --provenance       Record how each new message was generated in a git note")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - <range> must end at HEAD; a single revision R means R..HEAD.")
+	fmt.Println("  - Each commit's own diff is described; old and new messages are shown side by side.")
+	fmt.Println("  - The previous HEAD is saved under " + backupRefPrefix + "<timestamp> before rewriting.")
+	fmt.Println("  - Merge commits and commits already on the upstream branch are refused.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage reword origin/main..HEAD")
+	fmt.Println("  gessage reword HEAD~5")
+}
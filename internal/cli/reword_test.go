@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReword(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "tag", "base")
+	commitFile(t, "b.txt", "b\n", "wip one")
+	commitFile(t, "c.txt", "c\n", "wip two")
+	commitFile(t, "d.txt", "d\n", "fix: add d")
+	oldHead := gitT(t, "rev-parse", "HEAD")
+	trees := gitT(t, "log", "--format=%T", "base..HEAD")
+
+	// The third proposal is skipped; its commit is still rebuilt on the new parent
+	out, err := runGessage(t, "a\na\ns\n", []string{"feat: add b", "feat: add c", "fix: add d file"},
+		"reword", "--model", "fake", "base")
+	if err != nil {
+		t.Fatalf("gessage reword: %v\n%s", err, out)
+	}
+	if got, want := gitT(t, "log", "--format=%s", "base..HEAD"), "fix: add d\nfeat: add c\nfeat: add b"; got != want {
+		t.Errorf("messages after reword:\n%s\nwant:\n%s", got, want)
+	}
+	if got := gitT(t, "log", "--format=%T", "base..HEAD"); got != trees {
+		t.Errorf("trees changed:\n%s\nwant:\n%s", got, trees)
+	}
+	if got := gitT(t, "rev-parse", "HEAD~3"); got != gitT(t, "rev-parse", "base") {
+		t.Errorf("the rewritten range starts at %s, not base", got)
+	}
+	if got := gitT(t, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("HEAD is at %q after reword, want the branch main", got)
+	}
+	backups := gitT(t, "for-each-ref", "--format=%(objectname)", backupRefPrefix)
+	if backups != oldHead {
+		t.Errorf("backup refs point at %q, want the previous HEAD %s", backups, oldHead)
+	}
+}
+
+func TestRewordRefuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+		args  []string
+		err   string
+	}{
+		{
+			name: "merge",
+			setup: func(t *testing.T) {
+				gitT(t, "checkout", "-q", "-b", "topic")
+				commitFile(t, "b.txt", "b\n", "feat: add b")
+				gitT(t, "checkout", "-q", "main")
+				commitFile(t, "c.txt", "c\n", "feat: add c")
+				gitT(t, "merge", "-q", "--no-edit", "topic")
+			},
+			args: []string{"base"},
+			err:  "is a merge; reword only supports linear history",
+		},
+		{
+			name: "published",
+			setup: func(t *testing.T) {
+				commitFile(t, "b.txt", "b\n", "wip")
+				gitT(t, "branch", "published")
+				commitFile(t, "c.txt", "c\n", "wip")
+				gitT(t, "branch", "--set-upstream-to", "published")
+			},
+			args: []string{"base"},
+			err:  "is already on published; refusing to rewrite published history",
+		},
+		{
+			name: "range not at HEAD",
+			setup: func(t *testing.T) {
+				commitFile(t, "b.txt", "b\n", "wip")
+				commitFile(t, "c.txt", "c\n", "wip")
+			},
+			args: []string{"base..HEAD~1"},
+			err:  "range must end at HEAD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo(t)
+			commitFile(t, "a.txt", "a\n", "feat: add a")
+			gitT(t, "tag", "base")
+			tt.setup(t)
+			head := gitT(t, "rev-parse", "HEAD")
+			useFakeModel(t, "feat: reworded")
+
+			var err error
+			withStdio(t, "", func() {
+				err = NewApp().Run(context.Background(), append([]string{"reword", "--model", "fake"}, tt.args...))
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("reword = %v, want %q", err, tt.err)
+			}
+			if got := gitT(t, "rev-parse", "HEAD"); got != head {
+				t.Errorf("HEAD moved to %s", got)
+			}
+		})
+	}
+}
+
+func TestRewordPromptRedactsMessage(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	commitFile(t, "b.txt", "b\n", "fix: use token=0123456789abcdef")
+	c := useFakeModel(t, "fix: use the new token")
+
+	// Nothing answers the prompt, so reword stops after the first proposal
+	withStdio(t, "", func() { NewApp().Run(context.Background(), []string{"reword", "--model", "fake", "HEAD~1"}) })
+	if len(c.prompts) != 1 {
+		t.Fatalf("%d prompt(s), want 1", len(c.prompts))
+	}
+	if strings.Contains(c.prompts[0], "0123456789abcdef") {
+		t.Errorf("reword prompt leaks a secret from the commit message:\n%s", c.prompts[0])
+	}
+	if !strings.Contains(c.prompts[0], "fix: use [REDACTED]") {
+		t.Errorf("reword prompt does not include the redacted message:\n%s", c.prompts[0])
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// diffInput is a diff the root command describes instead of the index, from
+// --diff -, --diff-file or --range.
+type diffInput struct {
+	Stdin string // value of --diff; only "-" is accepted
+	File  string
+	Range string
+}
+
+// set reports whether any source other than the index was requested.
+func (in diffInput) set() bool {
+	return in.Stdin != "" || in.File != "" || in.Range != ""
+}
+
+// String names the source for messages: the patch file, the range, or stdin.
+func (in diffInput) String() string {
+	switch {
+	case in.File != "":
+		return in.File
+	case in.Range != "":
+		return in.Range
+	case in.Stdin != "":
+		return "the patch on stdin"
+	}
+	return "the index"
+}
+
+func (in diffInput) read(ctx context.Context) (diff.Diff, error) {
+	n := 0
+	for _, v := range []string{in.Stdin, in.File, in.Range} {
+		if v != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return diff.Diff{}, errors.New("use only one of --diff, --diff-file and --range")
+	}
+	switch {
+	case in.Stdin != "":
+		if in.Stdin != "-" {
+			return diff.Diff{}, errors.New(`--diff only accepts "-" (stdin); use --diff-file for a file`)
+		}
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return diff.Diff{}, err
+		}
+		return in.readPatch(string(b))
+	case in.File != "":
+		b, err := os.ReadFile(in.File)
+		if err != nil {
+			return diff.Diff{}, err
+		}
+		return in.readPatch(string(b))
+	default:
+		return rangeDiff(ctx, in.Range)
+	}
+}
+
+// readPatch parses text read from in, naming in when it holds no changes.
+func (in diffInput) readPatch(text string) (diff.Diff, error) {
+	d, err := diff.ReadPatch(text)
+	if errors.Is(err, diff.ErrNoPatch) {
+		return diff.Diff{}, fmt.Errorf("%s contains no changes (%w)", in, err)
+	}
+	return d, err
+}
+
+// rangeDiff returns the net change of A..B since the merge base of A and B,
+// or the change a single commit introduces.
+func rangeDiff(ctx context.Context, revRange string) (diff.Diff, error) {
+	if strings.Contains(revRange, "...") {
+		return diff.Diff{}, errors.New("symmetric ranges (A...B) are not supported; use A..B")
+	}
+	start, end, isRange := strings.Cut(revRange, "..")
+	if !isRange {
+		id, err := git.ResolveRev(ctx, revRange)
+		if err != nil {
+			return diff.Diff{}, err
+		}
+		return git.CommitDiff(ctx, id)
+	}
+	if start == "" {
+		return diff.Diff{}, errors.New("the range needs a start, e.g. main..HEAD")
+	}
+	if end == "" {
+		end = "HEAD"
+	}
+	base, err := git.MergeBase(ctx, start, end)
+	if err != nil {
+		return diff.Diff{}, err
+	}
+	return git.DiffRevs(ctx, base, end)
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRootEmptySource(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "commit", "-q", "--allow-empty", "-m", "chore: nothing")
+	writeFile(t, "empty.patch", "")
+	writeFile(t, "prose.patch", "Just some notes, no diff here.\n")
+
+	tests := []struct {
+		args  []string
+		stdin string
+		want  string
+	}{
+		{args: []string{"--diff-file", "empty.patch"}, want: "empty.patch contains no changes (no diff found in the input)"},
+		{args: []string{"--diff", "-"}, want: "the patch on stdin contains no changes (no diff found in the input)"},
+		{args: []string{"--diff-file", "prose.patch"}, want: "prose.patch contains no changes (no diff found in the input)"},
+		{args: []string{"--diff", "-"}, stdin: "Subject: notes\n\nNothing to apply.\n", want: "the patch on stdin contains no changes (no diff found in the input)"},
+		{args: []string{"--range", "HEAD"}, want: "HEAD contains no changes"},
+		{args: []string{"--range", "HEAD..HEAD"}, want: "HEAD..HEAD contains no changes"},
+	}
+	for _, tt := range tests {
+		var err error
+		withStdio(t, tt.stdin, func() { err = NewApp().Run(context.Background(), tt.args) })
+		if got := errString(err); got != tt.want {
+			t.Errorf("Run(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/diff"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+// splitHunkPreviewLines caps how much of each hunk the grouping prompt shows.
+const splitHunkPreviewLines = 40
+
+func (a *App) runSplit(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage split", flag.ContinueOnError)
+	fs.Usage = printSplitUsage
+	var (
+		flagModel      = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
+		flagHeuristic  = fs.Bool("heuristic", false, "Group hunks by directory instead of asking the model")
+		flagMaxTokens  = fs.Int("max-tokens", 512, "Max tokens for AI generation")
+		flagMaxBytes   = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI (after sanitization)")
+		flagDryRun     = fs.Bool("dry-run", false, "Show the proposed groups; do not commit")
+		flagProvenance = fs.Bool("provenance", false, "Record how each message was generated in a git note ("+git.NotesRef+")")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	// Step 1: Break the staged diff into hunks; binary files need the full
+	// patch data or 'git apply --cached' cannot stage them again
+	staged, err := git.GetStagedPatch(ctx)
+	if err != nil {
+		return err
+	}
+	if staged.Empty() {
+		return errors.New("no staged changes. Use `git add` first")
+	}
+	hunks := diff.SplitHunks(staged)
+	if len(hunks) < 2 {
+		return errors.New("only one hunk is staged; run `gessage` to commit it")
+	}
+
+	// Step 2: A client is optional here; without one we group heuristically
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	gen := generator{cfg: cfg, maxTokens: *flagMaxTokens, maxBytes: *flagMaxBytes, style: repoStyle(ctx)}
+	if modelName, err := resolveModelName(cfg, *flagModel, false, 0); err == nil {
+		if gen.client, err = newClient(cfg, modelName); err == nil {
+			gen.name = modelName
+			color.Cyan("Using model: %s", modelName)
+		} else {
+			color.Yellow("%v; grouping by directory.", err)
+		}
+	}
+
+	// Step 3: Propose groups and let the user review them
+	groups := proposeSplit(ctx, gen, hunks, *flagHeuristic, *flagMaxTokens)
+	for {
+		printSplitGroups(hunks, groups)
+		if *flagDryRun {
+			return nil
+		}
+		fmt.Print("\n[a]pprove  [h]euristic grouping  [c]ancel > ")
+		choice, err := ui.ReadChoice()
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case "a", "approve":
+			return commitSplit(ctx, gen, hunks, groups, *flagProvenance || cfg.Provenance)
+		case "h", "heuristic":
+			groups = groupByDirectory(hunks)
+		case "c", "cancel":
+			return errors.New("cancelled by user")
+		default:
+			color.Yellow("Unknown option: %s", choice)
+		}
+	}
+}
+
+// proposeSplit asks the model for a grouping and falls back to directories.
+func proposeSplit(ctx context.Context, gen generator, hunks []diff.Hunk, heuristic bool, maxTokens int) []format.SplitGroup {
+	if gen.client == nil || heuristic {
+		return groupByDirectory(hunks)
+	}
+	var previews []string
+	for _, h := range hunks {
+		previews = append(previews, redact(previewHunk(h)))
+	}
+	spin := ui.NewSpinner("Grouping hunks...")
+	spin.Start()
+	out, err := gen.client.Generate(ctx, format.BuildSplitPrompt(previews), maxTokens)
+	spin.Stop()
+	fmt.Println()
+	if err == nil {
+		if groups, ok := format.ParseSplitGroups(out, len(hunks)); ok {
+			return groups
+		}
+	}
+	color.Yellow("AI grouping failed or was incomplete; grouping by directory. err=%v", err)
+	printGenErrorHint(gen.name, err)
+	return groupByDirectory(hunks)
+}
+
+// previewHunk renders a hunk with its path, clipped for the grouping prompt.
+func previewHunk(h diff.Hunk) string {
+	if h.Binary {
+		return "File: " + h.Path + "\nBinary file changed"
+	}
+	lines := strings.Split(strings.TrimRight(h.Body, "\n"), "\n")
+	if len(lines) > splitHunkPreviewLines {
+		lines = append(lines[:splitHunkPreviewLines], "... [TRUNCATED]")
+	}
+	return "File: " + h.Path + "\n" + strings.Join(lines, "\n")
+}
+
+// groupByDirectory is the offline grouping: one group per parent directory.
+func groupByDirectory(hunks []diff.Hunk) []format.SplitGroup {
+	index := map[string]int{}
+	var groups []format.SplitGroup
+	for i, h := range hunks {
+		dir := path.Dir(h.Path)
+		gi, ok := index[dir]
+		if !ok {
+			gi = len(groups)
+			index[dir] = gi
+			groups = append(groups, format.SplitGroup{Label: dir})
+		}
+		groups[gi].Hunks = append(groups[gi].Hunks, i+1)
+	}
+	return groups
+}
+
+func printSplitGroups(hunks []diff.Hunk, groups []format.SplitGroup) {
+	for i, g := range groups {
+		color.White("\nGroup %d: %s", i+1, g.Label)
+		for _, id := range g.Hunks {
+			fmt.Printf("  %d) %s\n", id, hunks[id-1].Title())
+		}
+	}
+}
+
+// commitSplit stages and commits each group in turn. On any failure the index
+// is restored to what was staged before the split started.
+func commitSplit(ctx context.Context, gen generator, hunks []diff.Hunk, groups []format.SplitGroup, withProvenance bool) (err error) {
+	original, err := git.WriteTree(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rerr := git.ReadTree(ctx, original); rerr != nil {
+				err = fmt.Errorf("%w (restoring the index also failed: %v)", err, rerr)
+				return
+			}
+			color.Yellow("Index restored to the remaining staged changes.")
+		}
+	}()
+
+	issueKey, trailers := branchIssue(ctx)
+	for i, g := range groups {
+		if err := git.ResetIndexToHead(ctx); err != nil {
+			return err
+		}
+		ids := append([]int(nil), g.Hunks...)
+		sort.Ints(ids) // hunks must be applied in patch order
+		var selected []diff.Hunk
+		for _, id := range ids {
+			selected = append(selected, hunks[id-1])
+		}
+		if err := git.ApplyCached(ctx, diff.JoinHunks(selected)); err != nil {
+			return fmt.Errorf("stage group %d: %w", i+1, err)
+		}
+
+		groupDiff, err := git.GetStagedDiff(ctx)
+		if err != nil {
+			return err
+		}
+		msg := ""
+		var prov provenance
+		if gen.client != nil {
+			safe, redactions := prepareDiff(ctx, groupDiff, gen.maxBytes)
+			prompt := format.BuildPrompt(format.PromptInput{
+				Diff:     safe,
+				Types:    format.AllowedTypes,
+				MaxTitle: maxTitle,
+				MaxBody:  maxBody,
+				IssueKey: issueKey,
+				Style:    gen.style,
+			})
+			prov = newProvenance(gen.cfg, gen.name, prompt, redactions)
+			spin := ui.NewSpinner(fmt.Sprintf("Generating message for group %d/%d...", i+1, len(groups)))
+			spin.Start()
+			msg, err = gen.client.Generate(ctx, prompt, gen.maxTokens)
+			spin.Stop()
+			fmt.Println()
+			if err != nil {
+				color.Yellow("AI failed for group %d; using a fallback message. err=%v", i+1, err)
+				printGenErrorHint(gen.name, err)
+			}
+		}
+		if strings.TrimSpace(msg) == "" {
+			msg = format.FallbackFromDiff(groupDiff)
+			prov.Fallback = true
+		}
+		msg = format.AppendTrailers(format.NormalizeMessage(msg, commitNormalizeOptions("", gen.style, groupDiff.Paths())), trailers)
+
+		color.White("\n--- Group %d/%d ---\n", i+1, len(groups))
+		fmt.Println(msg)
+		if err := git.CommitWithMessage(ctx, msg, git.CommitOptions{}); err != nil {
+			return fmt.Errorf("commit group %d: %w", i+1, err)
+		}
+		if withProvenance && gen.client != nil {
+			recordProvenance(ctx, "HEAD", prov)
+		}
+		// Later groups apply on top of the new HEAD; the original tree stays the
+		// restore point because it still holds everything not yet committed.
+	}
+	color.Green("\nCreated %d commits.", len(groups))
+	return nil
+}
+
+func printSplitUsage() {
+	fmt.Println("gessage split - break a large staged diff into several logical commits")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage split [flags]")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --model string     AI model to use (e.g., gpt4-o, openrouter, ollama)")
+	fmt.Println("  --heuristic        Group hunks by directory instead of asking the model")
+	fmt.Println("  --max-tokens int   Max tokens for AI generation (default 512)")
+	fmt.Println("  --max-bytes int    Max diff bytes per group to send to AI (default 100000)")
+	fmt.Println("  --dry-run          Show the proposed groups; do not commit")
+	fmt.Println("  --provenance       Record how each message was generated in a git note")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - New, deleted, renamed and binary files are kept whole.")
+	fmt.Println("  - Each approved group is staged with 'git apply --cached' and committed with its own message.")
+	fmt.Println("  - If any step fails, the index is restored to the changes that are still uncommitted.")
+}
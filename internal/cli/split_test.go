@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+// stageGroups stages changes in api/, lib/ and web/, which --heuristic
+// splits into three groups, and leaves an unstaged edit alongside.
+func stageGroups(t *testing.T) {
+	t.Helper()
+	testRepo(t)
+	commitFile(t, "README.md", "readme\n", "docs: add readme")
+	writeFile(t, "api/server.go", "package api\n")
+	writeFile(t, "lib/util.go", "package lib\n")
+	writeFile(t, "web/app.js", "console.log('hi')\n")
+	gitT(t, "add", "api", "lib", "web")
+	writeFile(t, "README.md", "readme\nunstaged\n")
+}
+
+func TestSplit(t *testing.T) {
+	stageGroups(t)
+	out, err := runGessage(t, "a\n", []string{"feat(api): add the server", "feat(lib): add util", "feat(web): add the app"},
+		"split", "--model", "fake", "--heuristic")
+	if err != nil {
+		t.Fatalf("gessage split: %v\n%s", err, out)
+	}
+	if got, want := gitT(t, "log", "--format=%s", "-3"), "feat(web): add the app\nfeat(lib): add util\nfeat(api): add the server"; got != want {
+		t.Errorf("split commits:\n%s\nwant:\n%s", got, want)
+	}
+	if got := gitT(t, "show", "--name-only", "--format=", "HEAD~2"); got != "api/server.go" {
+		t.Errorf("first group committed %q, want api/server.go", got)
+	}
+	if got := gitT(t, "diff", "--cached", "--name-only"); got != "" {
+		t.Errorf("still staged after split: %q", got)
+	}
+	if got := gitT(t, "diff", "--name-only"); got != "README.md" {
+		t.Errorf("unstaged changes after split = %q, want README.md untouched", got)
+	}
+}
+
+func TestSplitRestoresIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		hook    string // pre-commit hook
+		commits string // subjects added by split
+	}{
+		{name: "cancelled", input: "c\n"},
+		{
+			// The lib group's commit is rejected; api's is kept and the
+			// index holds everything not yet committed, web included
+			name:    "commit fails",
+			input:   "a\n",
+			hook:    "#!/bin/sh\ngit diff --cached --name-only | grep -q '^lib/' && exit 1\nexit 0\n",
+			commits: "feat: split group",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stageGroups(t)
+			head := gitT(t, "rev-parse", "HEAD")
+			staged := gitT(t, "diff", "--cached", "--name-only")
+			if tt.hook != "" {
+				writeFile(t, ".git/hooks/pre-commit", tt.hook)
+				if err := os.Chmod(".git/hooks/pre-commit", 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			out, err := runGessage(t, tt.input, []string{"feat: split group"}, "split", "--model", "fake", "--heuristic")
+			if err == nil {
+				t.Fatalf("gessage split succeeded, want an error\n%s", out)
+			}
+			if got := gitT(t, "log", "--format=%s", head+"..HEAD"); got != tt.commits {
+				t.Errorf("commits added = %q, want %q", got, tt.commits)
+			}
+			want := staged
+			if tt.commits != "" {
+				want = "lib/util.go\nweb/app.js"
+			}
+			if got := gitT(t, "diff", "--cached", "--name-only"); got != want {
+				t.Errorf("staged after a failed split = %q, want %q\n%s", got, want, out)
+			}
+			if got := gitT(t, "diff", "--name-only"); got != "README.md" {
+				t.Errorf("unstaged changes = %q, want README.md untouched", got)
+			}
+		})
+	}
+}
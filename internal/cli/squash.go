@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/config"
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+	"github.com/ispooya/gessage-cli/internal/ui"
+)
+
+func (a *App) runSquash(ctx context.Context, argv []string) error {
+	fs := flag.NewFlagSet("gessage squash", flag.ContinueOnError)
+	fs.Usage = printSquashUsage
+	var (
+		flagModel      = fs.String("model", "", "AI model to use (e.g., gpt4-o, openrouter, ollama)")
+		flagAuto       = fs.Bool("auto", true, "Auto-select model based on diff size (overrides --model if needed)")
+		flagMaxTokens  = fs.Int("max-tokens", 512, "Max tokens for AI generation")
+		flagMaxBytes   = fs.Int("max-bytes", 100_000, "Max diff bytes to send to AI (after sanitization)")
+		flagDryRun     = fs.Bool("dry-run", false, "Print sanitized diff and prompt; do not call AI")
+		flagApply      = fs.Bool("apply", false, "Squash the range into one commit (git reset --soft + commit)")
+		flagProvenance = fs.Bool("provenance", false, "Record how the message was generated in a git note ("+git.NotesRef+")")
+	)
+	if err := fs.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 1 {
+		printSquashUsage()
+		return errors.New("expected exactly one commit range, e.g. origin/main..HEAD")
+	}
+
+	// Step 1: Resolve the range and where the squashed commit would start
+	revRange := fs.Arg(0)
+	if strings.Contains(revRange, "...") {
+		return errors.New("symmetric ranges (A...B) are not supported; use A..B")
+	}
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	start, end, _ := strings.Cut(revRange, "..")
+	if start == "" {
+		return errors.New("the range needs a start, e.g. main..HEAD")
+	}
+	if end == "" {
+		end = "HEAD"
+	}
+	endID, err := git.ResolveRev(ctx, end)
+	if err != nil {
+		return err
+	}
+	head, err := git.ResolveRev(ctx, "HEAD")
+	if err != nil {
+		return err
+	}
+	if *flagApply {
+		if endID != head {
+			return fmt.Errorf("--apply needs a range ending at HEAD; check out %s first", end)
+		}
+		staged, err := git.GetStagedDiff(ctx)
+		if err != nil {
+			return err
+		}
+		if !staged.Empty() {
+			return errors.New("the index has staged changes; commit or stash them before squashing")
+		}
+	}
+	base, err := git.MergeBase(ctx, start, endID)
+	if err != nil {
+		return err
+	}
+	commits, err := git.Log(ctx, "--reverse", "--no-merges", revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", revRange)
+	}
+
+	// Step 2: Collect subjects and every breaking change announced in the range
+	var subjects, breaking []string
+	seen := map[string]bool{}
+	for _, c := range commits {
+		subjects = append(subjects, redact(c.Subject())) // for the prompt only
+		cc, ok := format.ParseConventional(c.Message)
+		if !ok {
+			continue
+		}
+		for _, note := range cc.BreakingNotes() {
+			if !seen[note] {
+				seen[note] = true
+				breaking = append(breaking, note)
+			}
+		}
+	}
+
+	// Step 3: Describe the net change of the range
+	diff, err := git.DiffRevs(ctx, base, endID)
+	if err != nil {
+		return err
+	}
+	safe, redactions := prepareDiff(ctx, diff, *flagMaxBytes)
+	// The prompt gets the breaking notes redacted like the diff; the final
+	// message keeps them as written
+	safeBreaking := make([]string, len(breaking))
+	for i, note := range breaking {
+		safeBreaking[i] = redact(note)
+	}
+	prompt := format.BuildSquashPrompt(format.SquashPromptInput{
+		Diff:     safe,
+		Commits:  subjects,
+		Breaking: safeBreaking,
+		Types:    format.AllowedTypes,
+		MaxTitle: maxTitle,
+		MaxBody:  maxBody,
+	})
+	if *flagDryRun {
+		fmt.Println("=== [SANITIZED DIFF] ===")
+		fmt.Println(safe)
+		fmt.Println("\n=== [PROMPT] ===")
+		fmt.Println(prompt)
+		return nil
+	}
+
+	// Step 4: Generate via the configured client
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	modelName, err := resolveModelName(cfg, *flagModel, *flagAuto, len(safe.String()))
+	if err != nil {
+		color.Yellow("No model configured. Run: gessage setup")
+		return err
+	}
+	color.Cyan("Using model: %s", modelName)
+	client, err := newClient(cfg, modelName)
+	if err != nil {
+		return err
+	}
+
+	_, trailers := branchIssue(ctx)
+	style := repoStyle(ctx)
+	// The learned style is applied to generated text only, not to edits
+	finalize := func(m string, style *format.StyleProfile) string {
+		m = format.NormalizeMessage(m, commitNormalizeOptions("", style, diff.Paths()))
+		return format.AppendTrailers(format.KeepBreakingChanges(m, breaking), trailers)
+	}
+	prov := newProvenance(cfg, modelName, prompt, redactions)
+	// Stream only when the message goes to the confirmation prompt
+	msg, genErr := generateLive(ctx, client, fmt.Sprintf("Summarizing %d commits...", len(commits)), prompt, *flagMaxTokens, *flagApply)
+	fmt.Println()
+	if errors.Is(genErr, errAborted) {
+		return errors.New("generation aborted; history left untouched")
+	}
+	if genErr != nil || strings.TrimSpace(msg) == "" {
+		color.Yellow("AI failed or returned empty message. Falling back. err=%v", genErr)
+		printGenErrorHint(modelName, genErr)
+		msg = format.FallbackFromDiff(diff)
+		prov.Fallback = true
+	}
+	msg = finalize(msg, style)
+
+	if !*flagApply {
+		fmt.Println(msg)
+		return nil
+	}
+
+	// Step 5: Confirm before rewriting history
+	for {
+		color.White("\n--- Squash %d commits of %s into ---\n", len(commits), revRange)
+		fmt.Println(msg)
+		fmt.Print("\n[a]pprove  [e]dit  [r]egenerate  [c]ancel > ")
+
+		choice, err := ui.ReadChoice()
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case "a", "approve":
+			if err := applySquash(ctx, base, head, msg); err != nil {
+				return err
+			}
+			if *flagProvenance || cfg.Provenance {
+				recordProvenance(ctx, "HEAD", prov)
+			}
+			return nil
+		case "e", "edit":
+			edited, err := ui.EditInEditor(msg)
+			if err != nil {
+				return err
+			}
+			msg = finalize(edited, nil)
+			prov.Edited = true
+		case "r", "regenerate":
+			newMsg, err := generateLive(ctx, client, "Regenerating commit message...", prompt, *flagMaxTokens, true)
+			fmt.Println()
+			if errors.Is(err, errAborted) {
+				color.Yellow("Regenerate aborted; keeping existing proposal.")
+				continue
+			}
+			if err != nil || strings.TrimSpace(newMsg) == "" {
+				color.Yellow("Regenerate failed; keeping existing proposal.")
+				printGenErrorHint(modelName, err)
+				continue
+			}
+			msg = finalize(newMsg, style)
+			prov.Regenerations++
+			prov.Edited, prov.Fallback = false, false
+		case "c", "cancel":
+			return errors.New("cancelled by user; history left untouched")
+		default:
+			color.Yellow("Unknown option: %s", choice)
+		}
+	}
+}
+
+// applySquash replaces the commits between base and head with a single
+// commit holding head's tree. A backup ref keeps the old history reachable.
+func applySquash(ctx context.Context, base, head, msg string) error {
+	if upstream := git.Upstream(ctx); upstream != "" {
+		if published, err := git.IsAncestor(ctx, head, upstream); err == nil && published {
+			color.Yellow("These commits are already on %s; pushing the squash will need --force-with-lease.", upstream)
+		}
+	}
+	backup := backupRefPrefix + time.Now().Format("20060102-150405")
+	if err := git.UpdateRef(ctx, backup, head, "", "gessage squash: backup"); err != nil {
+		return err
+	}
+	if err := git.ResetSoft(ctx, base); err != nil {
+		return err
+	}
+	if err := git.CommitWithMessage(ctx, msg, git.CommitOptions{}); err != nil {
+		if rerr := git.ResetSoft(ctx, head); rerr != nil {
+			return fmt.Errorf("%w\nrestore with: git reset --soft %s", err, backup)
+		}
+		return err
+	}
+	color.Green("Squashed into one commit. Undo with: git reset --hard %s", backup)
+	return nil
+}
+
+func printSquashUsage() {
+	fmt.Println("gessage squash - summarize a range of commits as one Conventional Commit")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage squash [flags] <range>")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --model string     AI model to use (e.g., gpt4-o, openrouter, ollama)")
+	fmt.Println("  --auto             Auto-select model based on diff size (default true)")
+	fmt.Println("  --max-tokens int   Max tokens for AI generation (default 512)")
+	fmt.Println("  --max-bytes int    Max diff bytes to send to AI (default 100000)")
+	fmt.Println("  --dry-run          Print sanitized diff and prompt; do not call AI")
+	fmt.Println("  --apply            Squash the range into one commit (git reset --soft + commit)")
+	fmt.Println("  --provenance       Record how the message was generated in a git note")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - A single revision R means R..HEAD; the net diff is taken from the merge base.")
+	fmt.Println("  - Without --apply the message is printed, e.g. for a squash merge in the web UI.")
+	fmt.Println("  - BREAKING CHANGE footers (and '!' subjects) of the squashed commits are kept.")
+	fmt.Println("  - With --apply the range must end at HEAD and nothing may be staged; the previous")
+	fmt.Println("    HEAD is saved under " + backupRefPrefix + "<timestamp>.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  gessage squash main..HEAD")
+	fmt.Println("  gessage squash --apply origin/main")
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSquashPromptRedactsCommits(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "tag", "base")
+	commitFile(t, "b.txt", "b\n", "feat(api)!: drop v1\n\nBREAKING CHANGE: the old api_key=abcdef123456 is no longer accepted")
+	commitFile(t, "c.txt", "c\n", "fix: set token=zzzzzz999")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"squash", "--dry-run", "base..HEAD"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, prompt, _ := strings.Cut(out, "=== [PROMPT] ===")
+	for _, secret := range []string{"abcdef123456", "zzzzzz999"} {
+		if strings.Contains(prompt, secret) {
+			t.Errorf("squash prompt leaks %q:\n%s", secret, prompt)
+		}
+	}
+	for _, want := range []string{"fix: set [REDACTED]", "the old [REDACTED] is no longer accepted"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("squash prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
+
+// squashRange commits three changes on top of the tag "base", one of them
+// breaking, and leaves an unstaged edit.
+func squashRange(t *testing.T) {
+	t.Helper()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "tag", "base")
+	commitFile(t, "b.txt", "b\n", "feat: add b")
+	commitFile(t, "c.txt", "c\n", "feat(api)!: drop v1\n\nBREAKING CHANGE: v1 clients must upgrade")
+	commitFile(t, "b.txt", "b2\n", "fix: b")
+	writeFile(t, "a.txt", "unstaged\n")
+}
+
+func TestSquashApply(t *testing.T) {
+	squashRange(t)
+	head := gitT(t, "rev-parse", "HEAD")
+	tree := gitT(t, "rev-parse", "HEAD^{tree}")
+
+	out, err := runGessage(t, "a\n", []string{"feat: add b and c"}, "squash", "--apply", "--model", "fake", "base")
+	if err != nil {
+		t.Fatalf("gessage squash --apply: %v\n%s", err, out)
+	}
+	if got := gitT(t, "rev-parse", "HEAD~1"); got != gitT(t, "rev-parse", "base") {
+		t.Errorf("squashed commit's parent = %s, want base", got)
+	}
+	if got := gitT(t, "rev-parse", "HEAD^{tree}"); got != tree {
+		t.Errorf("squashed tree = %s, want %s", got, tree)
+	}
+	msg := gitT(t, "log", "-1", "--format=%B")
+	if !strings.HasPrefix(msg, "feat: add b and c") || !strings.Contains(msg, "BREAKING CHANGE: v1 clients must upgrade") {
+		t.Errorf("squashed message = %q, want the generated one with the breaking change kept", msg)
+	}
+	if backups := gitT(t, "for-each-ref", "--format=%(objectname)", backupRefPrefix); backups != head {
+		t.Errorf("backup refs point at %q, want the previous HEAD %s", backups, head)
+	}
+	if got := gitT(t, "status", "--porcelain"); got != "M a.txt" {
+		t.Errorf("status after squashing = %q, want only the unstaged edit", got)
+	}
+}
+
+func TestSquashApplyLeavesHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		setup func(t *testing.T)
+		args  []string
+		err   string
+	}{
+		{name: "cancelled", input: "c\n", err: "cancelled by user; history left untouched"},
+		{
+			name:  "commit fails",
+			input: "a\n",
+			setup: func(t *testing.T) {
+				writeFile(t, ".git/hooks/pre-commit", "#!/bin/sh\nexit 1\n")
+				if err := os.Chmod(".git/hooks/pre-commit", 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			err: "git commit failed",
+		},
+		{
+			name:  "staged changes",
+			setup: func(t *testing.T) { gitT(t, "add", "a.txt") },
+			err:   "the index has staged changes; commit or stash them before squashing",
+		},
+		{name: "range not at HEAD", args: []string{"base..HEAD~1"}, err: "--apply needs a range ending at HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			squashRange(t)
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			head := gitT(t, "rev-parse", "HEAD")
+			index := gitT(t, "write-tree")
+			args := tt.args
+			if args == nil {
+				args = []string{"base"}
+			}
+
+			out, err := runGessage(t, tt.input, []string{"feat: add b and c"}, append([]string{"squash", "--apply", "--model", "fake"}, args...)...)
+			if err == nil || !strings.Contains(out, "Error: "+tt.err) {
+				t.Errorf("gessage squash --apply = %v, want %q\n%s", err, tt.err, out)
+			}
+			if got := gitT(t, "rev-parse", "HEAD"); got != head {
+				t.Errorf("HEAD moved to %s", got)
+			}
+			if got := gitT(t, "write-tree"); got != index {
+				t.Errorf("index changed to %s, want %s", got, index)
+			}
+		})
+	}
+}
+
+func TestSquashPrint(t *testing.T) {
+	squashRange(t)
+	head := gitT(t, "rev-parse", "HEAD")
+	useFakeModel(t, "feat: add b and c")
+
+	var err error
+	out := withStdio(t, "", func() { err = NewApp().Run(context.Background(), []string{"squash", "--model", "fake", "base"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "feat: add b and c\n") || !strings.Contains(out, "BREAKING CHANGE: v1 clients must upgrade") {
+		t.Errorf("squash printed:\n%s", out)
+	}
+	if got := gitT(t, "rev-parse", "HEAD"); got != head {
+		t.Errorf("squash without --apply moved HEAD to %s", got)
+	}
+}
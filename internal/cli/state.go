@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// mergePrompt builds the prompt for concluding a merge: the subjects of the
+// merged commits plus, for conflicted paths, the final content compared to
+// both sides so the model can explain how each conflict was resolved.
+func mergePrompt(ctx context.Context, st git.State, maxBytes int) (string, error) {
+	// Every MERGE_HEAD entry counts: an octopus merge brings in several
+	commits, err := git.Log(ctx, append(append([]string{"--no-merges"}, st.Heads...), "^HEAD")...)
+	if err != nil {
+		return "", err
+	}
+	// Commit messages can quote secrets as well as diffs can
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, redact(c.Subject()))
+	}
+
+	var resolution string
+	if len(st.Conflicts) > 0 {
+		ours, err := git.DiffCached(ctx, "HEAD", st.Conflicts...)
+		if err != nil {
+			return "", err
+		}
+		// Each side gets an equal share of the budget
+		share := maxBytes / (1 + len(st.Heads))
+		safeOurs, _ := prepareDiff(ctx, ours, share)
+		resolution = "--- Compared to our side (HEAD) ---\n" + safeOurs.String()
+		for _, head := range st.Heads {
+			theirs, err := git.DiffCached(ctx, head, st.Conflicts...)
+			if err != nil {
+				return "", err
+			}
+			safeTheirs, _ := prepareDiff(ctx, theirs, share)
+			label := "the merged side"
+			if len(st.Heads) > 1 {
+				label = "merged side " + head[:7]
+			}
+			resolution += "\n--- Compared to " + label + " ---\n" + safeTheirs.String()
+		}
+	}
+
+	return format.BuildMergePrompt(format.MergePromptInput{
+		PreparedMessage: redact(st.PreparedMessage),
+		Commits:         subjects,
+		Conflicts:       st.Conflicts,
+		Resolution:      resolution,
+		Types:           format.AllowedTypes,
+		MaxTitle:        maxTitle,
+		MaxBody:         maxBody,
+	}), nil
+}
+
+// cherryPickMessage keeps the picked commit's original message and records
+// where it came from, as `git cherry-pick -x` would.
+func cherryPickMessage(ctx context.Context, st git.State, trailers []string) (string, error) {
+	orig, err := git.MessageOf(ctx, st.Head)
+	if err != nil {
+		return "", err
+	}
+	msg := format.AppendTrailers(strings.TrimSpace(orig), trailers)
+	return format.AppendCherryPickLine(msg, st.Head), nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mergeInProgress leaves a merge of the branch "topic" into main uncommitted.
+// topic's commit message quotes a secret.
+func mergeInProgress(t *testing.T) {
+	t.Helper()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "checkout", "-q", "-b", "topic")
+	commitFile(t, "b.txt", "b\n", "fix: rotate token=s3cr3t-value-123")
+	gitT(t, "checkout", "-q", "main")
+	commitFile(t, "c.txt", "c\n", "docs: add c")
+	gitT(t, "merge", "-q", "--no-ff", "--no-commit", "topic")
+}
+
+func TestMergePromptRedactsCommits(t *testing.T) {
+	mergeInProgress(t)
+	useFakeModel(t, "chore: merge topic")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, prompt, _ := strings.Cut(out, "=== [PROMPT] ===")
+	if strings.Contains(prompt, "s3cr3t-value-123") {
+		t.Errorf("merge prompt leaks a secret from a commit subject:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "fix: rotate [REDACTED]") {
+		t.Errorf("merge prompt does not list the redacted subject:\n%s", prompt)
+	}
+}
+
+func TestPreparedMessageRedacted(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	commitFile(t, "b.txt", "b\n", "fix: set password=hunter2hunter2")
+	gitT(t, "reset", "-q", "--soft", "HEAD~1")
+	// What `git merge --squash` leaves behind for the next commit
+	writeFile(t, ".git/SQUASH_MSG", "Squashed commit of the following:\n\n    fix: set password=hunter2hunter2\n")
+	useFakeModel(t, "fix: set the password")
+
+	var err error
+	out := withStdio(t, "", func() {
+		err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "hunter2hunter2") {
+		t.Errorf("prompt leaks a secret from SQUASH_MSG:\n%s", out)
+	}
+	if !strings.Contains(out, "Squashed commit of the following") {
+		t.Errorf("prompt does not include SQUASH_MSG:\n%s", out)
+	}
+}
+
+func TestCommitConcludesMerge(t *testing.T) {
+	mergeInProgress(t)
+	topic := gitT(t, "rev-parse", "topic")
+
+	out, err := runGessage(t, "a\n", []string{"chore: merge topic"}, "--model", "fake")
+	if err != nil {
+		t.Fatalf("gessage: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Detected merge in progress") {
+		t.Errorf("output does not mention the merge:\n%s", out)
+	}
+	if got := gitT(t, "log", "-1", "--format=%P %s"); !strings.HasSuffix(got, " "+topic+" chore: merge topic") {
+		t.Errorf("HEAD = %q, want a merge of topic with the generated message", got)
+	}
+	if _, err := os.Stat(".git/MERGE_HEAD"); !os.IsNotExist(err) {
+		t.Errorf("MERGE_HEAD left behind: %v", err)
+	}
+}
+
+func TestEmptyMergeIsNotRefused(t *testing.T) {
+	// "-s ours" records the merge without changing the tree
+	mergeInProgress(t)
+	gitT(t, "merge", "--abort")
+	gitT(t, "merge", "-q", "--no-commit", "-s", "ours", "topic")
+	useFakeModel(t, "chore: merge topic")
+
+	var err error
+	out := withStdio(t, "", func() { err = NewApp().Run(context.Background(), []string{"--model", "fake", "--dry-run"}) })
+	if err != nil {
+		t.Fatalf("dry run of a merge with no changes: %v", err)
+	}
+	if !strings.Contains(out, "=== [PROMPT] ===") {
+		t.Errorf("dry run of a merge with no changes printed no prompt:\n%s", out)
+	}
+}
+
+func TestStateRefusals(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+		args  []string
+		err   string
+	}{
+		{name: "amend during a merge", setup: mergeInProgress, args: []string{"--amend"}, err: "cannot --amend while a merge is in progress"},
+		{name: "paths during a merge", setup: mergeInProgress, args: []string{"c.txt"}, err: "cannot commit only some paths while a merge is in progress"},
+		{
+			name: "amend during a revert",
+			setup: func(t *testing.T) {
+				testRepo(t)
+				commitFile(t, "a.txt", "a\n", "feat: add a")
+				commitFile(t, "a.txt", "b\n", "fix: change a")
+				gitT(t, "revert", "--no-commit", "HEAD")
+			},
+			args: []string{"--amend"},
+			err:  "cannot --amend while a revert is in progress",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+			useFakeModel(t, "chore: x")
+			var err error
+			withStdio(t, "", func() {
+				err = NewApp().Run(context.Background(), append([]string{"--model", "fake"}, tt.args...))
+			})
+			if errString(err) != tt.err {
+				t.Errorf("gessage %s = %v, want %q", strings.Join(tt.args, " "), err, tt.err)
+			}
+		})
+	}
+}
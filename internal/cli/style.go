@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// styleFile is where `gessage style learn` stores the profile, relative to the
+// repository's common git directory so every worktree shares it.
+const styleFile = "gessage/style.json"
+
+func (a *App) runStyle(ctx context.Context, argv []string) error {
+	if len(argv) == 0 {
+		printStyleUsage()
+		return errors.New("missing style action (learn, show or forget)")
+	}
+	action, rest := argv[0], argv[1:]
+
+	fs := flag.NewFlagSet("gessage style "+action, flag.ContinueOnError)
+	fs.Usage = printStyleUsage
+	var flagMax = fs.Int("n", 500, "Number of recent commits to learn from")
+	if err := fs.Parse(rest); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	path, err := stylePath(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "learn":
+		if fs.NArg() > 1 {
+			printStyleUsage()
+			return errors.New("expected at most one revision")
+		}
+		rev := "HEAD"
+		if fs.NArg() == 1 {
+			rev = fs.Arg(0)
+		}
+		return learnStyle(ctx, path, rev, *flagMax)
+	case "show":
+		p, err := loadStyle(ctx)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("no style profile yet; run: gessage style learn")
+		}
+		printStyle(path, p)
+		return nil
+	case "forget":
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				color.Yellow("No style profile to remove")
+				return nil
+			}
+			return err
+		}
+		color.Green("Removed %s", path)
+		return nil
+	default:
+		printStyleUsage()
+		return fmt.Errorf("unknown style action %q", action)
+	}
+}
+
+func learnStyle(ctx context.Context, path, rev string, max int) error {
+	commits, err := git.LogPaths(ctx, "--no-merges", "-n", strconv.Itoa(max), rev)
+	if err != nil {
+		return err
+	}
+	var samples []format.StyleSample
+	for _, c := range commits {
+		subject := c.Subject()
+		if strings.HasPrefix(subject, "fixup!") || strings.HasPrefix(subject, "squash!") || strings.HasPrefix(subject, "Revert \"") {
+			continue
+		}
+		samples = append(samples, format.StyleSample{Message: c.Message, Paths: c.Paths})
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no commits to learn from in %s", rev)
+	}
+
+	p := format.LearnStyle(samples)
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return err
+	}
+	printStyle(path, &p)
+	return nil
+}
+
+// stylePath returns where the current repository's profile is stored.
+func stylePath(ctx context.Context) (string, error) {
+	repo, err := git.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repo.CommonDir, filepath.FromSlash(styleFile)), nil
+}
+
+// loadStyle reads the repository's learned profile. It returns nil without an
+// error when none has been learned. Examples are redacted because they are
+// sent to the AI provider along with the diff.
+func loadStyle(ctx context.Context) (*format.StyleProfile, error) {
+	path, err := stylePath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p format.StyleProfile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("parse %s: %w (run gessage style learn again)", path, err)
+	}
+	for i, ex := range p.Examples {
+		p.Examples[i] = redact(ex)
+	}
+	return &p, nil
+}
+
+// repoStyle is loadStyle for commands where the profile is optional:
+// problems are reported and generation continues without it.
+func repoStyle(ctx context.Context) *format.StyleProfile {
+	p, err := loadStyle(ctx)
+	if errors.Is(err, git.ErrNotRepository) {
+		return nil // e.g. describing a patch outside any repository
+	}
+	if err != nil {
+		color.Yellow("Ignoring style profile: %v", err)
+		return nil
+	}
+	return p
+}
+
+func printStyle(path string, p *format.StyleProfile) {
+	color.Cyan("Style profile (%s)", path)
+	fmt.Printf("  commits analyzed:  %d\n", p.Commits)
+	fmt.Printf("  conventional:      %.0f%%\n", p.Conventional*100)
+	casing := p.Casing
+	if casing == "" {
+		casing = "mixed"
+	}
+	fmt.Printf("  description case:  %s\n", casing)
+	fmt.Printf("  scoped:            %.0f%%\n", p.ScopeUsage*100)
+	fmt.Printf("  body format:       %s\n", p.BodyFormat)
+	fmt.Printf("  title length:      ~%d characters\n", p.TitleLength)
+	if len(p.PathScopes) > 0 {
+		fmt.Println("  scopes by path:")
+		for _, ps := range p.PathScopes {
+			fmt.Printf("    %-30s %s (%d)\n", ps.Path, ps.Scope, ps.Count)
+		}
+	}
+	if len(p.Examples) > 0 {
+		fmt.Println("  examples:")
+		for _, ex := range p.Examples {
+			for _, ln := range strings.Split(ex, "\n") {
+				fmt.Println(strings.TrimRight("    "+ln, " "))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func printStyleUsage() {
+	fmt.Println("gessage style - learn the repository's commit message style")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  gessage style learn [-n <count>] [<rev>]")
+	fmt.Println("  gessage style show")
+	fmt.Println("  gessage style forget")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  -n int             Number of recent commits to learn from (default 500)")
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - The profile is stored in .git/" + styleFile + " and is never committed.")
+	fmt.Println("  - It records description casing, scopes per path, body format, typical title")
+	fmt.Println("    length and a few sample messages.")
+	fmt.Println("  - Generated messages follow the profile: samples are added to the prompt and the")
+	fmt.Println("    title's casing and scope are adjusted. Run learn again as the history grows.")
+}
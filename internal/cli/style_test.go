@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStyleAppliesToGeneratedMessagesOnly(t *testing.T) {
+	testRepo(t)
+	for i := range 12 {
+		commitFile(t, fmt.Sprintf("api/h%d.go", i), "package api\n", fmt.Sprintf("feat(api): add handler %d", i))
+	}
+	if out, err := runGessage(t, "", nil, "style", "learn"); err != nil {
+		t.Fatalf("gessage style learn: %v\n%s", err, out)
+	}
+	writeFile(t, "api/z.go", "package api\n")
+	gitT(t, "add", "api/z.go")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "generated", input: "a\n", want: "feat(api): add the z handler"},
+		{name: "edited", input: "e\nfix: Add Z handling\n.\na\n", want: "fix: Add Z handling"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runGessage(t, tt.input, []string{"feat: Add the z handler"}, "--model", "fake", "--no-commit")
+			if err != nil {
+				t.Fatalf("gessage: %v\n%s", err, out)
+			}
+			_, final, ok := strings.Cut(out, "[NO-COMMIT] Final message:\n")
+			if !ok || strings.TrimSpace(final) != tt.want {
+				t.Errorf("final message = %q, want %q\n%s", final, tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// stageOptions selects which working-tree changes the root command stages
+// before generating a message.
+type stageOptions struct {
+	All              bool     // --all: tracked modifications and deletions
+	IncludeUntracked bool     // --include-untracked: new files as well
+	Paths            []string // pathspecs limiting both the prompt and the commit
+}
+
+func (o stageOptions) any() bool {
+	return o.All || o.IncludeUntracked || len(o.Paths) > 0
+}
+
+// stageWorkingTree stages the changes selected by opt and returns a function
+// that puts the index back the way it was. Without any option it stages
+// nothing and the restore function is a no-op.
+func stageWorkingTree(ctx context.Context, opt stageOptions) (restore func(), err error) {
+	restore = func() {}
+	if !opt.any() {
+		return restore, nil
+	}
+	saved, err := git.WriteTree(ctx)
+	if err != nil {
+		return restore, fmt.Errorf("cannot save the index before staging (unresolved conflicts?): %w", err)
+	}
+	restore = func() {
+		if err := git.ReadTree(ctx, saved); err != nil {
+			color.Yellow("Could not restore the index: %v", err)
+		}
+	}
+
+	switch {
+	case opt.IncludeUntracked:
+		err = git.AddAll(ctx, opt.Paths...)
+	default:
+		// Pathspecs on their own behave like `git commit <paths>`: tracked files only
+		err = git.AddTracked(ctx, opt.Paths...)
+	}
+	if err != nil {
+		restore()
+		return func() {}, err
+	}
+	return restore, nil
+}
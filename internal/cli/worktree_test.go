@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRootStaging(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string // files in the described diff
+		wantErr string
+	}{
+		{name: "index", want: []string{"staged.txt"}},
+		{name: "all", args: []string{"--all"}, want: []string{"staged.txt", "sub/tracked.txt", "tracked.txt"}},
+		{
+			name: "include untracked",
+			args: []string{"--include-untracked"},
+			want: []string{"new.txt", "staged.txt", "sub/new.txt", "sub/tracked.txt", "tracked.txt"},
+		},
+		{name: "pathspec", args: []string{"sub"}, want: []string{"sub/tracked.txt"}},
+		{name: "pathspec with untracked", args: []string{"--include-untracked", "sub"}, want: []string{"sub/new.txt", "sub/tracked.txt"}},
+		{name: "pathspec without changes", args: []string{"none.txt"}, wantErr: "no changes to commit in none.txt"},
+		{name: "amend with pathspec", args: []string{"--amend", "sub"}, wantErr: "--amend cannot be limited to paths"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo(t)
+			writeFile(t, "tracked.txt", "one\n")
+			writeFile(t, "sub/tracked.txt", "one\n")
+			writeFile(t, "none.txt", "same\n")
+			gitT(t, "add", ".")
+			gitT(t, "commit", "-q", "-m", "feat: initial")
+			writeFile(t, "tracked.txt", "two\n")
+			writeFile(t, "sub/tracked.txt", "two\n")
+			writeFile(t, "new.txt", "new\n")
+			writeFile(t, "sub/new.txt", "new\n")
+			writeFile(t, "staged.txt", "staged\n")
+			gitT(t, "add", "staged.txt")
+			index := gitT(t, "write-tree")
+			useFakeModel(t, "chore: update")
+
+			var err error
+			out := withStdio(t, "", func() {
+				err = NewApp().Run(context.Background(), append([]string{"--model", "fake", "--dry-run"}, tt.args...))
+			})
+			if got := errString(err); got != tt.wantErr {
+				t.Fatalf("Run(%q) error = %q, want %q", tt.args, got, tt.wantErr)
+			}
+			if got := describedFiles(out); tt.wantErr == "" && strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Run(%q) described %q, want %q", tt.args, got, tt.want)
+			}
+			if got := gitT(t, "write-tree"); got != index {
+				t.Errorf("Run(%q) left the index at %s, want it restored to %s", tt.args, got, index)
+			}
+		})
+	}
+}
+
+// describedFiles lists the files of the "diff --git" headers in a dry run's
+// sanitized diff, in order.
+func describedFiles(out string) []string {
+	out, _, _ = strings.Cut(out, "=== [PROMPT] ===")
+	var files []string
+	for _, ln := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(ln, "diff --git a/"); ok {
+			path, _, _ := strings.Cut(rest, " b/")
+			files = append(files, path)
+		}
+	}
+	return files
+}
@@ -10,9 +10,20 @@ import (
 
 // Config stores the selected model and per-model configuration maps.
 // Models[modelName] is an arbitrary string map owned by that model implementation.
+// A "provider" key in that map makes modelName a named instance of another
+// provider; the cli registers it under modelName before using it.
 type Config struct {
 	SelectedModel string                       `json:"selected_model"`
 	Models        map[string]map[string]string `json:"models"`
+	// Pairs maps a short alias to a co-author identity ("Name <email>"),
+	// selected per commit with --pair.
+	Pairs map[string]string `json:"pairs,omitempty"`
+	// Provenance attaches a git note describing how each generated message
+	// was produced (same as passing --provenance).
+	Provenance bool `json:"provenance,omitempty"`
+	// Context is the default for --context: "function" or a number of
+	// context lines around each hunk.
+	Context string `json:"context,omitempty"`
 }
 
 // Default returns an empty configuration.
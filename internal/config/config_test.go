@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLoadKeepsInstances(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CONFIG_HOME")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	file := `{"selected_model": "lmstudio", "models": {
+		"lmstudio": {"provider": "openai-compatible", "base_url": "http://localhost:1234"},
+		"broken": {"provider": "no-such-provider"}
+	}}`
+	if err := os.MkdirAll(filepath.Join(dir, "gessage"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gessage", "config.json"), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// An unknown provider is not Load's concern; it is reported when used.
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SelectedModel != "lmstudio" || cfg.Models["lmstudio"]["provider"] != "openai-compatible" || cfg.Models["broken"]["provider"] != "no-such-provider" {
+		t.Errorf("Load() = %+v", cfg)
+	}
+}
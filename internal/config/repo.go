@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RepoFile is the per-repository settings file, read from the working tree root
+// so it can be committed and shared by a team.
+const RepoFile = ".gessage.json"
+
+// RepoConfig stores settings that belong to a repository rather than a user.
+type RepoConfig struct {
+	// IssuePatterns extract an issue key from the current branch name.
+	// The first matching pattern wins.
+	IssuePatterns []IssuePattern `json:"issue_patterns"`
+}
+
+// IssuePattern maps a branch-name regex to the trailer added to commit messages.
+// The key is the named group "key", else the first group, else the whole match.
+// Trailer is a template where {key} is replaced, e.g. "Refs: {key}" or "Closes #{key}".
+type IssuePattern struct {
+	Pattern string `json:"pattern"`
+	Trailer string `json:"trailer"`
+
+	re *regexp.Regexp
+}
+
+// LoadRepo reads RepoFile from root. A missing file yields an empty config.
+func LoadRepo(root string) (*RepoConfig, error) {
+	b, err := os.ReadFile(filepath.Join(root, RepoFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &RepoConfig{}, nil
+		}
+		return nil, err
+	}
+	var rc RepoConfig
+	if err := json.Unmarshal(b, &rc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", RepoFile, err)
+	}
+	for i := range rc.IssuePatterns {
+		p := &rc.IssuePatterns[i]
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: issue pattern %q: %w", RepoFile, p.Pattern, err)
+		}
+		p.re = re
+		if strings.TrimSpace(p.Trailer) == "" {
+			p.Trailer = "Refs: {key}"
+		}
+	}
+	return &rc, nil
+}
+
+// IssueFromBranch returns the issue key and rendered trailer for branch.
+func (rc *RepoConfig) IssueFromBranch(branch string) (key, trailer string, ok bool) {
+	for _, p := range rc.IssuePatterns {
+		if p.re == nil {
+			continue
+		}
+		m := p.re.FindStringSubmatch(branch)
+		if m == nil {
+			continue
+		}
+		key = m[0]
+		if i := p.re.SubexpIndex("key"); i > 0 && m[i] != "" {
+			key = m[i]
+		} else if len(m) > 1 && m[1] != "" {
+			key = m[1]
+		}
+		return key, strings.ReplaceAll(p.Trailer, "{key}", key), true
+	}
+	return "", "", false
+}
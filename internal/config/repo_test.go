@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIssueFromBranch(t *testing.T) {
+	dir := t.TempDir()
+	file := `{"issue_patterns": [
+		{"pattern": "(?P<key>[A-Z]+-\\d+)", "trailer": "Refs: {key}"},
+		{"pattern": "^issue-(\\d+)", "trailer": "Closes #{key}"},
+		{"pattern": "^hotfix/\\w+"}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, RepoFile), []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := LoadRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		branch, key, trailer string
+		ok                   bool
+	}{
+		{"feature/PAY-482-refunds", "PAY-482", "Refs: PAY-482", true},
+		{"issue-17-login", "17", "Closes #17", true},
+		{"issue-PAY-9", "PAY-9", "Refs: PAY-9", true}, // first matching pattern wins
+		{"hotfix/crash", "hotfix/crash", "Refs: hotfix/crash", true},
+		{"main", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		key, trailer, ok := rc.IssueFromBranch(tt.branch)
+		if key != tt.key || trailer != tt.trailer || ok != tt.ok {
+			t.Errorf("IssueFromBranch(%q) = %q, %q, %v; want %q, %q, %v", tt.branch, key, trailer, ok, tt.key, tt.trailer, tt.ok)
+		}
+	}
+}
+
+func TestLoadRepo(t *testing.T) {
+	rc, err := LoadRepo(t.TempDir())
+	if err != nil || len(rc.IssuePatterns) != 0 {
+		t.Errorf("LoadRepo(missing) = %+v, %v; want an empty config", rc, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, RepoFile), []byte(`{"issue_patterns": [{"pattern": "("}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRepo(dir); err == nil {
+		t.Error("LoadRepo accepted an invalid pattern")
+	}
+}
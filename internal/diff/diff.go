@@ -0,0 +1,178 @@
+// Package diff models and parses unified diffs. It runs no commands; the git
+// package produces diffs from a repository and the format package turns them
+// into prompts and messages.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileStatus is what happened to a file in a diff.
+type FileStatus string
+
+const (
+	StatusModified    FileStatus = "modified"
+	StatusAdded       FileStatus = "added"
+	StatusDeleted     FileStatus = "deleted"
+	StatusRenamed     FileStatus = "renamed"
+	StatusCopied      FileStatus = "copied"
+	StatusModeChanged FileStatus = "mode changed"
+)
+
+// FileDiff is one file of a diff.
+type FileDiff struct {
+	// OldPath and NewPath are the paths before and after the change; the
+	// missing side of an added or deleted file is "".
+	OldPath, NewPath string
+	Status           FileStatus
+	OldMode, NewMode string
+	Binary           bool
+	Submodule        bool
+	// Added and Deleted are line counts (from --numstat when available);
+	// both are 0 for binary files.
+	Added, Deleted int
+	// Header is the raw "diff --git" header up to the first hunk.
+	Header string
+	Hunks  []Hunk
+}
+
+// Path is the path the file has after the change, or had before a deletion.
+func (f FileDiff) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// Patch renders the file back into unified diff text.
+func (f FileDiff) Patch() string {
+	var b strings.Builder
+	b.WriteString(f.Header)
+	for _, h := range f.Hunks {
+		b.WriteString(h.Body)
+	}
+	return b.String()
+}
+
+// Summary is a one-line description such as "renamed a.go -> b.go (+3 -1)".
+func (f FileDiff) Summary() string {
+	var s string
+	switch f.Status {
+	case StatusRenamed, StatusCopied:
+		s = fmt.Sprintf("%s %s -> %s", f.Status, f.OldPath, f.NewPath)
+	case StatusModeChanged:
+		s = fmt.Sprintf("%s %s (%s -> %s)", f.Status, f.Path(), f.OldMode, f.NewMode)
+	default:
+		s = string(f.Status) + " " + f.Path()
+	}
+	switch {
+	case f.Submodule:
+		s += " (submodule)"
+	case f.Binary:
+		s += " (binary)"
+	case f.Added > 0 || f.Deleted > 0:
+		s += fmt.Sprintf(" (+%d -%d)", f.Added, f.Deleted)
+	}
+	return s
+}
+
+// Diff is a parsed diff. Omitted lists files dropped by Truncate; they are
+// still counted by Stats and summarized by String.
+type Diff struct {
+	Files   []FileDiff
+	Omitted []FileDiff
+
+	// Source is set when git produced the diff.
+	Source Source
+}
+
+// Source records how a diff was produced so that the git package can run it
+// again with other options or read the files it changed. It is zero for
+// parsed patches and for diffs derived with Truncate.
+type Source struct {
+	Args []string // the diff-producing git command, e.g. "diff", "--staged", "--"
+	Post string   // revision prefix of the new side: "rev:", or ":" for the index
+}
+
+// Empty reports whether the diff contains no files.
+func (d Diff) Empty() bool {
+	return len(d.Files) == 0 && len(d.Omitted) == 0
+}
+
+// All returns the kept and the omitted files.
+func (d Diff) All() []FileDiff {
+	return append(append([]FileDiff(nil), d.Files...), d.Omitted...)
+}
+
+// Stats returns the number of added and deleted lines over all files.
+func (d Diff) Stats() (added, deleted int) {
+	for _, f := range d.All() {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	return added, deleted
+}
+
+// Paths returns the path of every file in the diff.
+func (d Diff) Paths() []string {
+	var paths []string
+	for _, f := range d.All() {
+		paths = append(paths, f.Path())
+	}
+	return paths
+}
+
+// String renders the diff as unified diff text, followed by a note listing
+// any files dropped by Truncate. Without omissions the text is exactly what
+// git produced and can be applied.
+func (d Diff) String() string {
+	var b strings.Builder
+	for _, f := range d.Files {
+		b.WriteString(f.Patch())
+	}
+	if len(d.Omitted) > 0 {
+		fmt.Fprintf(&b, "\n... [TRUNCATED] %d more file(s) not shown:\n", len(d.Omitted))
+		for _, f := range d.Omitted {
+			b.WriteString("- " + f.Summary() + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Truncate keeps files, in order, while the rendered patch fits in maxBytes.
+// A file that does not fit keeps the hunks that do, or moves to Omitted when
+// none does; smaller files after it are still considered. maxBytes <= 0
+// means no limit.
+func (d Diff) Truncate(maxBytes int) Diff {
+	if maxBytes <= 0 {
+		return d
+	}
+	var out Diff
+	used := 0
+	for _, f := range d.Files {
+		if size := len(f.Patch()); used+size <= maxBytes {
+			out.Files = append(out.Files, f)
+			used += size
+			continue
+		}
+		partial := f
+		partial.Hunks = nil
+		size := len(f.Header)
+		for _, h := range f.Hunks {
+			if used+size+len(h.Body) > maxBytes {
+				break
+			}
+			partial.Hunks = append(partial.Hunks, h)
+			size += len(h.Body)
+		}
+		if len(partial.Hunks) == 0 {
+			out.Omitted = append(out.Omitted, f)
+			continue
+		}
+		out.Files = append(out.Files, partial)
+		used += size
+	}
+	out.Omitted = append(out.Omitted, d.Omitted...)
+	return out
+}
@@ -0,0 +1,92 @@
+package diff
+
+import "strings"
+
+// Hunk is a unit of a patch that can be staged on its own: one "@@" section
+// of a file, or a whole file when the change cannot be split (new, deleted,
+// renamed, binary or mode-only changes).
+type Hunk struct {
+	Path   string
+	Header string // file header lines ("diff --git" up to "+++"), shared by hunks of a file
+	Body   string // "@@" line and content, or everything after Header for atomic files
+	Atomic bool
+	Binary bool
+	// Line ranges from the "@@ -OldStart,OldLines +NewStart,NewLines @@" line;
+	// zero for atomic hunks.
+	OldStart, OldLines int
+	NewStart, NewLines int
+}
+
+// Title is a short one-line label for the hunk.
+func (h Hunk) Title() string {
+	if h.Atomic {
+		return h.Path + " (whole file)"
+	}
+	first, _, _ := strings.Cut(h.Body, "\n")
+	return h.Path + " " + first
+}
+
+// SplitHunks breaks a diff into independently applicable hunks.
+func SplitHunks(d Diff) []Hunk {
+	var hunks []Hunk
+	for _, f := range d.Files {
+		if len(f.Hunks) == 0 || f.Status != StatusModified || f.Binary {
+			body := strings.TrimPrefix(f.Patch(), f.Header)
+			hunks = append(hunks, Hunk{Path: f.Path(), Header: f.Header, Body: body, Atomic: true, Binary: f.Binary})
+			continue
+		}
+		hunks = append(hunks, f.Hunks...)
+	}
+	return hunks
+}
+
+// JoinHunks renders hunks back into a patch, writing each file header once.
+// Hunks must be passed in their original order.
+func JoinHunks(hunks []Hunk) string {
+	var b strings.Builder
+	lastHeader := ""
+	for _, h := range hunks {
+		if h.Header != lastHeader {
+			b.WriteString(h.Header)
+			lastHeader = h.Header
+		}
+		b.WriteString(h.Body)
+	}
+	return b.String()
+}
+
+// splitFiles splits a diff into per-file patches starting at "diff --git".
+func splitFiles(diff string) []string {
+	return splitAt(diff, "diff --git ")
+}
+
+// splitHeader separates a file patch into its header and its "@@" hunks.
+func splitHeader(file string) (header, hunks string) {
+	if i := strings.Index(file, "\n@@"); i >= 0 {
+		return file[:i+1], file[i+1:]
+	}
+	return file, ""
+}
+
+// splitAt splits s before every line that starts with prefix.
+func splitAt(s, prefix string) []string {
+	var parts []string
+	start := -1
+	for i := 0; i < len(s); {
+		if (i == 0 || s[i-1] == '\n') && strings.HasPrefix(s[i:], prefix) {
+			if start >= 0 {
+				parts = append(parts, s[start:i])
+			}
+			start = i
+		}
+		next := strings.IndexByte(s[i:], '\n')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	if start >= 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
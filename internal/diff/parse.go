@@ -0,0 +1,293 @@
+package diff
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ParseDiff parses patch text and refines it with `--numstat -z` output of
+// the same diff. When the two disagree on the number of files the patch
+// alone is used.
+func ParseDiff(patch, numstat string) Diff {
+	d := ParsePatch(patch)
+	stats := parseNumstat(numstat)
+	if len(stats) != len(d.Files) {
+		return d
+	}
+	for i, st := range stats {
+		f := &d.Files[i]
+		f.Added, f.Deleted = st.added, st.deleted
+		if st.binary {
+			f.Binary = true
+		}
+		switch f.Status {
+		case StatusAdded:
+			f.NewPath = st.newPath
+		case StatusDeleted:
+			f.OldPath = st.oldPath
+		default:
+			f.OldPath, f.NewPath = st.oldPath, st.newPath
+		}
+	}
+	return d
+}
+
+// ParsePatch parses unified diff text as produced by `git diff -p`. Paths
+// come from the headers and line counts from the hunks; text before the
+// first "diff --git" line is ignored.
+func ParsePatch(patch string) Diff {
+	return parseFiles(splitFiles(patch))
+}
+
+// parseFiles parses file patches, each a header followed by "@@" hunks.
+func parseFiles(files []string) Diff {
+	var d Diff
+	for _, file := range files {
+		header, rest := splitHeader(file)
+		f := parseFileHeader(header)
+		if rest != "" {
+			for _, body := range splitAt(rest, "@@") {
+				body = trimHunk(body)
+				h := parseHunk(f.Path(), header, body)
+				f.Hunks = append(f.Hunks, h)
+				for _, ln := range strings.Split(body, "\n")[1:] {
+					switch {
+					case strings.HasPrefix(ln, "+"):
+						f.Added++
+					case strings.HasPrefix(ln, "-"):
+						f.Deleted++
+					}
+				}
+			}
+		}
+		if !strings.HasPrefix(header, "diff --git ") && len(f.Hunks) == 1 && f.Status == StatusModified {
+			// diff -N marks a missing side with a timestamp, not /dev/null
+			switch h := f.Hunks[0]; {
+			case h.OldStart == 0 && h.OldLines == 0:
+				f.Status, f.OldPath = StatusAdded, ""
+			case h.NewStart == 0 && h.NewLines == 0:
+				f.Status, f.NewPath = StatusDeleted, ""
+			}
+		}
+		d.Files = append(d.Files, f)
+	}
+	return d
+}
+
+func parseFileHeader(header string) FileDiff {
+	f := FileDiff{Header: header, Status: StatusModified}
+	var minus, plus string
+	for _, ln := range strings.Split(header, "\n") {
+		switch {
+		case strings.HasPrefix(ln, "new file mode "):
+			f.Status, f.NewMode = StatusAdded, strings.TrimPrefix(ln, "new file mode ")
+		case strings.HasPrefix(ln, "deleted file mode "):
+			f.Status, f.OldMode = StatusDeleted, strings.TrimPrefix(ln, "deleted file mode ")
+		case strings.HasPrefix(ln, "old mode "):
+			f.OldMode = strings.TrimPrefix(ln, "old mode ")
+		case strings.HasPrefix(ln, "new mode "):
+			f.NewMode = strings.TrimPrefix(ln, "new mode ")
+			if f.Status == StatusModified {
+				f.Status = StatusModeChanged
+			}
+		case strings.HasPrefix(ln, "rename from "):
+			f.Status, f.OldPath = StatusRenamed, UnquotePath(strings.TrimPrefix(ln, "rename from "))
+		case strings.HasPrefix(ln, "rename to "):
+			f.NewPath = UnquotePath(strings.TrimPrefix(ln, "rename to "))
+		case strings.HasPrefix(ln, "copy from "):
+			f.Status, f.OldPath = StatusCopied, UnquotePath(strings.TrimPrefix(ln, "copy from "))
+		case strings.HasPrefix(ln, "copy to "):
+			f.NewPath = UnquotePath(strings.TrimPrefix(ln, "copy to "))
+		case strings.HasPrefix(ln, "index "):
+			f.Submodule = strings.HasSuffix(ln, " 160000")
+			// "index <a>..<b> <mode>" carries the mode when it did not change
+			if fields := strings.Fields(ln); len(fields) == 3 && f.OldMode == "" && f.NewMode == "" {
+				f.OldMode, f.NewMode = fields[2], fields[2]
+			}
+		case strings.HasPrefix(ln, "Binary files ") || ln == "GIT binary patch":
+			f.Binary = true
+		case strings.HasPrefix(ln, "--- "):
+			minus = strings.TrimPrefix(UnquotePath(headerPath(strings.TrimPrefix(ln, "--- "))), "a/")
+		case strings.HasPrefix(ln, "+++ "):
+			plus = strings.TrimPrefix(UnquotePath(headerPath(strings.TrimPrefix(ln, "+++ "))), "b/")
+		}
+	}
+	if f.Status == StatusModified && minus == "/dev/null" {
+		f.Status = StatusAdded // plain unified diffs have no "new file mode" line
+	}
+	if f.Status == StatusModified && plus == "/dev/null" {
+		f.Status = StatusDeleted
+	}
+	if f.OldPath == "" && minus != "" && minus != "/dev/null" {
+		f.OldPath = minus
+	}
+	if f.NewPath == "" && plus != "" && plus != "/dev/null" {
+		f.NewPath = plus
+	}
+	if f.OldPath == "" && f.NewPath == "" {
+		// Mode-only and binary changes have no ---/+++ lines; both sides of
+		// "diff --git a/<p> b/<p>" are then the same path.
+		first, _, _ := strings.Cut(header, "\n")
+		if rest := strings.TrimPrefix(first, "diff --git "); len(rest) >= 5 && (len(rest)-1)%2 == 0 {
+			p := UnquotePath(rest[:(len(rest)-1)/2])
+			p = strings.TrimPrefix(p, "a/")
+			f.OldPath, f.NewPath = p, p
+		}
+	}
+	switch f.Status {
+	case StatusAdded:
+		f.OldPath = ""
+	case StatusDeleted:
+		f.NewPath = ""
+	}
+	return f
+}
+
+// headerPath returns the path of a ---/+++ line. A tab ends it: git appends
+// one to paths with spaces, diff -u and svn follow it with a timestamp or
+// revision. Git quotes paths that contain a tab.
+func headerPath(s string) string {
+	p, _, _ := strings.Cut(s, "\t")
+	return p
+}
+
+// trimHunk cuts a hunk after the lines its "@@" header announces, dropping
+// whatever follows in patches that were not produced by git diff: mail
+// signatures, the headers of the next mail, "Index:" lines and the like.
+// Headers that cannot be read leave the hunk unchanged.
+func trimHunk(body string) string {
+	first, _, _ := strings.Cut(body, "\n")
+	fields := strings.Fields(first)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return body
+	}
+	_, oldLines := parseRange(strings.TrimPrefix(fields[1], "-"))
+	_, newLines := parseRange(strings.TrimPrefix(fields[2], "+"))
+	end := len(first) + 1
+	for end < len(body) && (oldLines > 0 || newLines > 0 || strings.HasPrefix(body[end:], "\\")) {
+		ln, _, _ := strings.Cut(body[end:], "\n")
+		switch {
+		case strings.HasPrefix(ln, "-"):
+			oldLines--
+		case strings.HasPrefix(ln, "+"):
+			newLines--
+		case strings.HasPrefix(ln, "\\"): // "\ No newline at end of file"
+		default: // context, including blank lines whose space was stripped
+			oldLines--
+			newLines--
+		}
+		end += len(ln) + 1
+	}
+	if end >= len(body) {
+		return body
+	}
+	return body[:end]
+}
+
+func parseHunk(path, header, body string) Hunk {
+	h := Hunk{Path: path, Header: header, Body: body}
+	first, _, _ := strings.Cut(body, "\n")
+	// "@@ -OldStart[,OldLines] +NewStart[,NewLines] @@ context"
+	fields := strings.Fields(first)
+	if len(fields) >= 3 {
+		h.OldStart, h.OldLines = parseRange(strings.TrimPrefix(fields[1], "-"))
+		h.NewStart, h.NewLines = parseRange(strings.TrimPrefix(fields[2], "+"))
+	}
+	return h
+}
+
+func parseRange(s string) (start, lines int) {
+	a, b, hasCount := strings.Cut(s, ",")
+	start, _ = strconv.Atoi(a)
+	if !hasCount {
+		return start, 1
+	}
+	lines, _ = strconv.Atoi(b)
+	return start, lines
+}
+
+// UnquotePath decodes a C-style quoted path as git prints paths with
+// special characters; other paths are returned unchanged.
+func UnquotePath(p string) string {
+	if strings.HasPrefix(p, `"`) {
+		if u, err := strconv.Unquote(p); err == nil {
+			return u
+		}
+	}
+	return p
+}
+
+type numstatEntry struct {
+	added, deleted   int
+	binary           bool
+	oldPath, newPath string
+}
+
+// parseNumstat parses `--numstat -z` output: "<added>\t<deleted>\t<path>\0",
+// or for renames and copies "<added>\t<deleted>\t\0<old>\0<new>\0". Binary
+// files report "-" for both counts.
+func parseNumstat(out string) []numstatEntry {
+	var entries []numstatEntry
+	tokens := strings.Split(out, "\x00")
+	for i := 0; i < len(tokens); i++ {
+		parts := strings.SplitN(tokens[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		e := numstatEntry{binary: parts[0] == "-"}
+		e.added, _ = strconv.Atoi(parts[0])
+		e.deleted, _ = strconv.Atoi(parts[1])
+		if parts[2] != "" {
+			e.oldPath, e.newPath = parts[2], parts[2]
+		} else if i+2 < len(tokens) {
+			e.oldPath, e.newPath = tokens[i+1], tokens[i+2]
+			i += 2
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// ErrNoPatch is returned by ReadPatch when the input contains no file changes.
+var ErrNoPatch = errors.New("no diff found in the input")
+
+// ReadPatch parses a patch that did not come from this repository: `git diff`
+// or `git format-patch` output (one or several mails), or a plain unified diff
+// as written by diff -u, svn or hg. Mail headers, commit messages and
+// diffstats around the changes are ignored.
+func ReadPatch(text string) (Diff, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var d Diff
+	if strings.HasPrefix(text, "diff --git ") || strings.Contains(text, "\ndiff --git ") {
+		d = ParsePatch(text)
+	} else {
+		d = parseFiles(splitUnified(text))
+	}
+	if d.Empty() {
+		return Diff{}, ErrNoPatch
+	}
+	return d, nil
+}
+
+// splitUnified splits a plain unified diff before every "--- " line that is
+// followed by a "+++ " line.
+func splitUnified(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	var files []string
+	start := -1
+	for i := 0; i+1 < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") || !strings.HasPrefix(lines[i+1], "+++ ") {
+			continue
+		}
+		if start >= 0 {
+			files = append(files, strings.Join(lines[start:i], ""))
+		}
+		start = i
+		i++
+	}
+	if start >= 0 {
+		files = append(files, strings.Join(lines[start:], ""))
+	}
+	return files
+}
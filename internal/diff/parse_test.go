@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stagedPatch is `git diff --staged -p` output for a binary change, a path
+// with a space, a rename with edits and a mode-only change.
+const stagedPatch = "diff --git a/logo.png b/logo.png\n" +
+	"index 8352675..ef2caff 100644\n" +
+	"Binary files a/logo.png and b/logo.png differ\n" +
+	"diff --git a/my file.txt b/my file.txt\n" +
+	"index 587be6b..b77b4eb 100644\n" +
+	"--- a/my file.txt\t\n" +
+	"+++ b/my file.txt\t\n" +
+	"@@ -1 +1,2 @@\n" +
+	" x\n" +
+	"+y\n" +
+	"diff --git a/old.txt b/new.txt\n" +
+	"similarity index 73%\n" +
+	"rename from old.txt\n" +
+	"rename to new.txt\n" +
+	"index 4cb29ea..f384549 100644\n" +
+	"--- a/old.txt\n" +
+	"+++ b/new.txt\n" +
+	"@@ -1,3 +1,4 @@\n" +
+	" one\n" +
+	" two\n" +
+	" three\n" +
+	"+four\n" +
+	"diff --git a/run.sh b/run.sh\n" +
+	"old mode 100644\n" +
+	"new mode 100755\n"
+
+// stagedNumstat is `git diff --staged --numstat -z` for the same change.
+const stagedNumstat = "-\t-\tlogo.png\x00" +
+	"1\t0\tmy file.txt\x00" +
+	"1\t0\t\x00old.txt\x00new.txt\x00" +
+	"0\t0\trun.sh\x00"
+
+type fileSummary struct {
+	OldPath, NewPath string
+	Status           FileStatus
+	OldMode, NewMode string
+	Binary           bool
+	Added, Deleted   int
+	Hunks            int
+}
+
+func summarize(d Diff) []fileSummary {
+	var out []fileSummary
+	for _, f := range d.Files {
+		out = append(out, fileSummary{f.OldPath, f.NewPath, f.Status, f.OldMode, f.NewMode, f.Binary, f.Added, f.Deleted, len(f.Hunks)})
+	}
+	return out
+}
+
+func TestParsePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  []fileSummary
+	}{
+		{
+			name:  "staged changes",
+			patch: stagedPatch,
+			want: []fileSummary{
+				{"logo.png", "logo.png", StatusModified, "100644", "100644", true, 0, 0, 0},
+				{"my file.txt", "my file.txt", StatusModified, "100644", "100644", false, 1, 0, 1},
+				{"old.txt", "new.txt", StatusRenamed, "100644", "100644", false, 1, 0, 1},
+				{"run.sh", "run.sh", StatusModeChanged, "100644", "100755", false, 0, 0, 0},
+			},
+		},
+		{
+			name: "quoted path and new file",
+			patch: "diff --git \"a/caf\\303\\251.txt\" \"b/caf\\303\\251.txt\"\n" +
+				"new file mode 100644\n" +
+				"index 0000000..257cc56\n" +
+				"--- /dev/null\n" +
+				"+++ \"b/caf\\303\\251.txt\"\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+foo\n",
+			want: []fileSummary{{"", "café.txt", StatusAdded, "", "100644", false, 1, 0, 1}},
+		},
+		{
+			name: "deleted binary",
+			patch: "diff --git a/img/a b.gif b/img/a b.gif\n" +
+				"deleted file mode 100644\n" +
+				"index 1c3b2a0..0000000\n" +
+				"Binary files a/img/a b.gif and /dev/null differ\n",
+			want: []fileSummary{{"img/a b.gif", "", StatusDeleted, "100644", "", true, 0, 0, 0}},
+		},
+		{
+			name: "plain unified diff",
+			patch: "--- a/x.txt\t2024-01-01 10:00:00\n" +
+				"+++ b/x.txt\t2024-01-02 10:00:00\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-a\n" +
+				"+b\n" +
+				" c\n",
+			want: nil, // ParsePatch needs "diff --git" headers; ReadPatch handles these
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize(ParsePatch(tt.patch)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePatch() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatchRoundTrip(t *testing.T) {
+	if got := ParsePatch(stagedPatch).String(); got != stagedPatch {
+		t.Errorf("String() does not reproduce the patch:\n%s", got)
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []numstatEntry
+	}{
+		{
+			name: "staged changes",
+			in:   stagedNumstat,
+			want: []numstatEntry{
+				{binary: true, oldPath: "logo.png", newPath: "logo.png"},
+				{added: 1, oldPath: "my file.txt", newPath: "my file.txt"},
+				{added: 1, oldPath: "old.txt", newPath: "new.txt"},
+				{oldPath: "run.sh", newPath: "run.sh"},
+			},
+		},
+		{
+			name: "paths are not quoted with -z",
+			in:   "3\t1\tdir/tab\there.go\x00",
+			want: []numstatEntry{{added: 3, deleted: 1, oldPath: "dir/tab\there.go", newPath: "dir/tab\there.go"}},
+		},
+		{name: "empty", in: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNumstat(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNumstat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	d := ParseDiff(stagedPatch, stagedNumstat)
+	if added, deleted := d.Stats(); added != 2 || deleted != 0 {
+		t.Errorf("Stats() = +%d -%d, want +2 -0", added, deleted)
+	}
+	want := []string{"logo.png", "my file.txt", "new.txt", "run.sh"}
+	if got := d.Paths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths() = %q, want %q", got, want)
+	}
+	// A numstat that disagrees on the file count is ignored.
+	if got := summarize(ParseDiff(stagedPatch, "1\t0\tother\x00")); !reflect.DeepEqual(got, summarize(ParsePatch(stagedPatch))) {
+		t.Errorf("ParseDiff with a mismatched numstat = %+v", got)
+	}
+}
@@ -1,22 +1,43 @@
 package format
 
-// (replace the entire tail of the file with this)
-
 import (
 	"bufio"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
 )
 
+// TruncateTitle cuts title to at most max characters (runes), never inside
+// a multi-byte UTF-8 sequence.
+func TruncateTitle(title string, max int) string {
+	if utf8.RuneCountInString(title) <= max {
+		return title
+	}
+	return strings.TrimRight(string([]rune(title)[:max]), " ")
+}
+
 var AllowedTypes = []string{"feat", "fix", "refactor", "docs", "chore", "style", "test", "perf"}
 
 type PromptInput struct {
-	Diff         string
+	// Diff is the redacted and truncated staged diff.
+	Diff         diff.Diff
 	Types        []string
 	MaxTitle     int
 	MaxBody      int
 	UserTypeHint string
+	// PreviousMessage is the message of the commit being amended, if any.
+	PreviousMessage string
+	// IssueKey is the issue referenced by the branch name, if any.
+	IssueKey string
+	// PreparedMessage is a message git prepared for this commit (e.g. SQUASH_MSG).
+	PreparedMessage string
+	// Style is the repository's learned commit style, if any.
+	Style *StyleProfile
+	// Context holds full files shown after the diff for small changes.
+	Context []FileContent
 }
 
 func BuildPrompt(in PromptInput) string {
@@ -25,6 +46,20 @@ func BuildPrompt(in PromptInput) string {
 	if in.UserTypeHint != "" {
 		hint = "\nUser-specified type hint: " + in.UserTypeHint
 	}
+	if in.IssueKey != "" {
+		hint += "\nRelated issue: " + in.IssueKey + " (a reference footer is added automatically; do not add one)"
+	}
+	if strings.TrimSpace(in.PreparedMessage) != "" {
+		hint += "\nGit prepared the message below for this commit. Start from it and rewrite it as a" +
+			"\nConventional Commit that describes the diff:\n" + strings.TrimSpace(in.PreparedMessage) + "\n"
+	}
+	if strings.TrimSpace(in.PreviousMessage) != "" {
+		hint += "\nThis amends an existing commit. Its current message is below; keep what still" +
+			"\napplies and update it to describe the full diff:\n" + strings.TrimSpace(in.PreviousMessage) + "\n"
+	}
+	if in.Style != nil {
+		hint += in.Style.promptHint(in.Diff.Paths())
+	}
 	return `Generate a Conventional Commit message from the following staged git diff.
 Constraints:
 - title <= ` + strconv.Itoa(in.MaxTitle) + ` characters
@@ -38,9 +73,8 @@ Output format:
 
 ` + hint + `
 
-Diff:
-` + in.Diff + `
-`
+` + describeDiff(in.Diff) + `
+` + describeContext(in.Context)
 }
 
 type NormalizeOptions struct {
@@ -48,6 +82,10 @@ type NormalizeOptions struct {
 	MaxBody     int
 	Types       []string
 	DefaultType string
+	// Style, when set, adjusts the title's casing and scope to the
+	// repository's habits; Paths are the changed files used to pick a scope.
+	Style *StyleProfile
+	Paths []string
 }
 
 func NormalizeMessage(msg string, opt NormalizeOptions) string {
@@ -58,7 +96,7 @@ func NormalizeMessage(msg string, opt NormalizeOptions) string {
 	lines := strings.Split(msg, "\n")
 	titleIdx := -1
 	var title string
-	titleRe := regexp.MustCompile(`(?i)^(feat|fix|refactor|docs|chore|style|test|perf)(\([^)]+\))?:\s+.+$`)
+	titleRe := regexp.MustCompile(`(?i)^(feat|fix|refactor|docs|chore|style|test|perf)(\([^)]+\))?!?:\s+.+$`)
 	for i, ln := range lines {
 		l := strings.TrimSpace(ln)
 		if l == "" {
@@ -88,9 +126,10 @@ func NormalizeMessage(msg string, opt NormalizeOptions) string {
 	if !containsCaseInsensitive(opt.Types, ty) {
 		title = opt.DefaultType + ": " + title
 	}
-	if len(title) > opt.MaxTitle {
-		title = title[:opt.MaxTitle]
+	if opt.Style != nil {
+		title = opt.Style.applyTitle(title, opt.Style.ScopeFor(opt.Paths))
 	}
+	title = TruncateTitle(title, opt.MaxTitle)
 
 	// Body is the text after the title, filtered to remove instructions/tables
 	var bodyLines []string
@@ -121,16 +160,24 @@ func NormalizeMessage(msg string, opt NormalizeOptions) string {
 	return title + "\n\n" + body
 }
 
-func FallbackFromDiff(diff string) string {
-	added, removed, files := countDiffStats(diff)
-	if len(files) == 0 {
-		files = []string{"files"}
+// FallbackFromDiff builds a message from the diff alone when the model fails.
+func FallbackFromDiff(d diff.Diff) string {
+	files := d.All()
+	added, removed := d.Stats()
+	names := d.Paths()
+	if len(names) == 0 {
+		names = []string{"files"}
 	}
-	title := "chore: update " + strings.Join(files, ", ")
+	title := "chore: " + fallbackVerb(files) + " " + strings.Join(names, ", ")
 	if len(title) > 72 {
 		title = title[:72]
 	}
 	var body []string
+	for _, f := range files {
+		if f.Status != diff.StatusModified || f.Binary {
+			body = append(body, "- "+f.Summary())
+		}
+	}
 	if added > 0 {
 		body = append(body, "- Additions: "+strconv.Itoa(added))
 	}
@@ -169,7 +216,7 @@ func wrapLines(s string, width int) string {
 }
 
 func leadingType(first string) string {
-	re := regexp.MustCompile(`^([a-z]+)(\([\w\-\./]+\))?:`)
+	re := regexp.MustCompile(`^([a-z]+)(\([\w\-\./]+\))?!?:`)
 	m := re.FindStringSubmatch(strings.ToLower(first))
 	if len(m) >= 2 {
 		return m[1]
@@ -202,26 +249,3 @@ func stripNonCommitNoise(s string) string {
 	}
 	return strings.Join(out, "\n")
 }
-
-func countDiffStats(diff string) (added, removed int, files []string) {
-	seen := map[string]bool{}
-	sc := bufio.NewScanner(strings.NewReader(diff))
-	for sc.Scan() {
-		ln := sc.Text()
-		if strings.HasPrefix(ln, "+++ b/") || strings.HasPrefix(ln, "--- a/") {
-			// collect file names
-			name := strings.TrimPrefix(strings.TrimPrefix(ln, "+++ b/"), "--- a/")
-			if name != "/dev/null" && name != "" && !seen[name] {
-				seen[name] = true
-				files = append(files, name)
-			}
-		}
-		if strings.HasPrefix(ln, "+") && !strings.HasPrefix(ln, "+++") {
-			added++
-		}
-		if strings.HasPrefix(ln, "-") && !strings.HasPrefix(ln, "---") {
-			removed++
-		}
-	}
-	return
-}
@@ -0,0 +1,67 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// describeDiff renders the file list (with status and line counts from the
+// diff model) followed by the patch, so the model sees renames, deletions and
+// binary files explicitly even when the patch was truncated.
+func describeDiff(d diff.Diff) string {
+	var b strings.Builder
+	b.WriteString("Files changed:\n")
+	for _, f := range d.All() {
+		b.WriteString("- " + f.Summary() + "\n")
+	}
+	b.WriteString("\nDiff:\n")
+	b.WriteString(d.String())
+	return b.String()
+}
+
+// FileContent is the full text of a file, given to the model as context.
+type FileContent struct {
+	Path    string
+	Content string
+}
+
+// describeContext renders full files, fenced off from the diff so the model
+// does not mistake unchanged code for part of the change.
+func describeContext(files []FileContent) string {
+	if len(files) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nContext only, NOT part of the change: the full content of the touched files after the change.\n")
+	for _, f := range files {
+		b.WriteString("=== BEGIN CONTEXT " + f.Path + " ===\n")
+		b.WriteString(strings.TrimRight(f.Content, "\n") + "\n")
+		b.WriteString("=== END CONTEXT " + f.Path + " ===\n")
+	}
+	return b.String()
+}
+
+// fallbackVerb picks the title verb when every file had the same fate.
+func fallbackVerb(files []diff.FileDiff) string {
+	verb := ""
+	for i, f := range files {
+		v := "update"
+		switch f.Status {
+		case diff.StatusAdded:
+			v = "add"
+		case diff.StatusDeleted:
+			v = "remove"
+		case diff.StatusRenamed:
+			v = "rename"
+		}
+		if i > 0 && v != verb {
+			return "update"
+		}
+		verb = v
+	}
+	if verb == "" {
+		return "update"
+	}
+	return verb
+}
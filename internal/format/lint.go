@@ -0,0 +1,156 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a rule a commit message breaks. Line and Column are 1-based
+// and refer to the message as given, comment lines included.
+type Violation struct {
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%d:%d: %s [%s]", v.Line, v.Column, v.Message, v.Rule)
+}
+
+// scissors marks the start of the diff `git commit --verbose` adds below the
+// message; git discards it, and so does the linter.
+const scissors = "# ------------------------ >8 ------------------------"
+
+type messageLine struct {
+	n    int
+	text string
+}
+
+// LintMessage checks msg against the rules NormalizeMessage enforces: an
+// allowed type in "<type>(scope): <description>" form, the title length, a
+// blank line after the title and the body width. Lines starting with '#' are
+// comments and ignored, as `git commit` does. Merge, revert and
+// fixup!/squash!/amend! messages written by git are not checked.
+func LintMessage(msg string, opt NormalizeOptions) []Violation {
+	lines := messageLines(msg)
+	if len(lines) == 0 {
+		return []Violation{{Line: 1, Column: 1, Rule: "empty", Message: "message is empty"}}
+	}
+	title := lines[0]
+	if isGitGenerated(title.text) {
+		return nil
+	}
+
+	var out []Violation
+	m := subjectRe.FindStringSubmatch(title.text)
+	switch {
+	case m == nil:
+		out = append(out, Violation{Line: title.n, Column: 1, Rule: "title-format",
+			Message: `title must look like "<type>(<scope>): <description>"`})
+	case !containsCaseInsensitive(opt.Types, m[1]):
+		out = append(out, Violation{Line: title.n, Column: 1, Rule: "type-enum",
+			Message: fmt.Sprintf("type %q is not one of: %s", m[1], strings.Join(opt.Types, ", "))})
+	}
+	if n := utf8.RuneCountInString(title.text); n > opt.MaxTitle {
+		out = append(out, Violation{Line: title.n, Column: opt.MaxTitle + 1, Rule: "title-max-length",
+			Message: fmt.Sprintf("title is %d characters, the limit is %d", n, opt.MaxTitle)})
+	}
+	if len(lines) > 1 && lines[1].text != "" {
+		out = append(out, Violation{Line: lines[1].n, Column: 1, Rule: "body-leading-blank",
+			Message: "the title must be followed by a blank line"})
+	}
+
+	body, _ := splitTrailers(lines[1:])
+	for _, ln := range body {
+		if n := utf8.RuneCountInString(ln.text); n > opt.MaxBody {
+			out = append(out, Violation{Line: ln.n, Column: opt.MaxBody + 1, Rule: "body-max-line-length",
+				Message: fmt.Sprintf("body line is %d characters, the limit is %d", n, opt.MaxBody)})
+		}
+	}
+	return out
+}
+
+// FixMessage rewrites msg through NormalizeMessage, dropping comments and
+// keeping a trailing trailer block verbatim so long trailers are not wrapped.
+// Messages LintMessage does not check are returned without comments only.
+func FixMessage(msg string, opt NormalizeOptions) string {
+	lines := messageLines(msg)
+	if len(lines) == 0 {
+		return ""
+	}
+	if isGitGenerated(lines[0].text) {
+		return joinLines(lines)
+	}
+	body, trailers := splitTrailers(lines[1:])
+	fixed := NormalizeMessage(joinLines(append(lines[:1:1], body...)), opt)
+	if len(trailers) == 0 {
+		return fixed
+	}
+	return fixed + "\n\n" + joinLines(trailers)
+}
+
+// messageLines returns the lines git would keep: comments and everything
+// below the scissors line are dropped, trailing whitespace and surrounding
+// blank lines trimmed. Line numbers refer to msg.
+func messageLines(msg string) []messageLine {
+	var lines []messageLine
+	for i, ln := range strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n") {
+		if ln == scissors {
+			break
+		}
+		if strings.HasPrefix(ln, "#") {
+			continue
+		}
+		ln = strings.TrimRight(ln, " \t")
+		if ln == "" && len(lines) == 0 {
+			continue
+		}
+		lines = append(lines, messageLine{n: i + 1, text: ln})
+	}
+	for len(lines) > 0 && lines[len(lines)-1].text == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitTrailers separates a final trailer paragraph from the body lines.
+func splitTrailers(lines []messageLine) (body, trailers []messageLine) {
+	start := len(lines)
+	for start > 0 && lines[start-1].text != "" {
+		start--
+	}
+	if start == 0 || start == len(lines) {
+		// Without a blank line before it the last paragraph continues the
+		// title, so it is not a trailer block.
+		return lines, nil
+	}
+	if _, ok := parseFooters(joinLines(lines[start:])); !ok {
+		return lines, nil
+	}
+	body = lines[:start]
+	for len(body) > 0 && body[len(body)-1].text == "" {
+		body = body[:len(body)-1]
+	}
+	return body, lines[start:]
+}
+
+func joinLines(lines []messageLine) string {
+	texts := make([]string, len(lines))
+	for i, ln := range lines {
+		texts[i] = ln.text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// isGitGenerated reports titles git writes itself for merges, reverts and
+// autosquash commits.
+func isGitGenerated(title string) bool {
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(title, prefix) {
+			return true
+		}
+	}
+	return false
+}
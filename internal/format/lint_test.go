@@ -0,0 +1,107 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var lintOptions = NormalizeOptions{MaxTitle: 72, MaxBody: 100, Types: AllowedTypes, DefaultType: "chore"}
+
+func TestLintMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want []string // "<line>:<column>:<rule>"
+	}{
+		{name: "plain", msg: "feat: add login"},
+		{name: "scope", msg: "fix(api): handle empty body\n\nThe handler panicked on nil.\n"},
+		{name: "breaking", msg: "feat(api)!: drop v1"},
+		{name: "breaking without scope", msg: "refactor!: rename config keys"},
+		{name: "merge", msg: "Merge branch 'main' into topic"},
+		{name: "revert", msg: "Revert \"feat: add login\"\n\nThis reverts commit abc.\n"},
+		{name: "fixup", msg: "fixup! feat: add login"},
+		{name: "comments ignored", msg: "# Please enter the commit message\nfeat: add login\n# On branch main\n"},
+		{
+			name: "verbose diff ignored",
+			msg:  "feat: add login\n" + scissors + "\ndiff --git a/x b/x\n" + strings.Repeat("+", 200) + "\n",
+		},
+		{name: "empty", msg: "# only a comment\n\n", want: []string{"1:1:empty"}},
+		{name: "no type", msg: "add login", want: []string{"1:1:title-format"}},
+		{name: "unknown type", msg: "feature: add login", want: []string{"1:1:type-enum"}},
+		{name: "type case", msg: "Feat: add login"},
+		{
+			name: "long title",
+			msg:  "feat: " + strings.Repeat("a", 70),
+			want: []string{"1:73:title-max-length"},
+		},
+		{
+			name: "no blank line",
+			msg:  "feat: add login\nwith a form",
+			want: []string{"2:1:body-leading-blank"},
+		},
+		{
+			name: "long body line",
+			msg:  "# comment\nfeat: add login\n\n" + strings.Repeat("b", 101),
+			want: []string{"4:101:body-max-line-length"},
+		},
+		{
+			name: "long trailer exempt",
+			msg:  "feat: add login\n\nBody.\n\nCo-authored-by: " + strings.Repeat("c", 100) + " <c@example.com>",
+		},
+		{
+			name: "several",
+			msg:  "wip " + strings.Repeat("x", 80) + "\nmore",
+			want: []string{"1:1:title-format", "1:73:title-max-length", "2:1:body-leading-blank"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range LintMessage(tt.msg, lintOptions) {
+				got = append(got, fmt.Sprintf("%d:%d:%s", v.Line, v.Column, v.Rule))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LintMessage(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{name: "passing", msg: "feat: add login\n", want: "feat: add login"},
+		{name: "breaking", msg: "feat(api)!: drop v1", want: "feat(api)!: drop v1"},
+		{name: "breaking without scope", msg: "refactor!: rename config keys", want: "refactor!: rename config keys"},
+		{name: "missing type", msg: "add login", want: "chore: add login"},
+		{name: "comments dropped", msg: "# comment\nfix: typo\n# On branch main\n", want: "fix: typo"},
+		{
+			name: "long title truncated",
+			msg:  "feat: " + strings.Repeat("a", 70),
+			want: "feat: " + strings.Repeat("a", 66),
+		},
+		{
+			name: "body wrapped",
+			msg:  "fix: typo\n\n" + strings.Repeat("word ", 30),
+			want: "fix: typo\n\n" + strings.TrimSpace(strings.Repeat("word ", 20)) + "\n" + strings.TrimSpace(strings.Repeat("word ", 10)),
+		},
+		{
+			name: "trailers kept verbatim",
+			msg:  "fix: typo\n\nBody.\n\nCo-authored-by: " + strings.Repeat("c", 100) + " <c@example.com>\nRefs: PAY-1\n",
+			want: "fix: typo\n\nBody.\n\nCo-authored-by: " + strings.Repeat("c", 100) + " <c@example.com>\nRefs: PAY-1",
+		},
+		{name: "merge untouched", msg: "Merge branch 'main'\n\n# Conflicts:\n", want: "Merge branch 'main'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FixMessage(tt.msg, lintOptions); got != tt.want {
+				t.Errorf("FixMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	"strconv"
+	"strings"
+)
+
+type MergePromptInput struct {
+	PreparedMessage string   // git's MERGE_MSG, e.g. "Merge branch 'feature/x'"
+	Commits         []string // subjects of the commits being merged in
+	Conflicts       []string // paths that had conflicts
+	Resolution      string   // diff of the conflicted paths against both sides
+	Types           []string
+	MaxTitle        int
+	MaxBody         int
+}
+
+// BuildMergePrompt asks for a merge commit message that summarizes the merged
+// commits and explains how conflicts were resolved.
+func BuildMergePrompt(in MergePromptInput) string {
+	var b strings.Builder
+	b.WriteString(`Generate a Conventional Commit message for a merge commit.
+Constraints:
+- title <= ` + strconv.Itoa(in.MaxTitle) + ` characters, naming what was merged
+- optional body lines <= ` + strconv.Itoa(in.MaxBody) + ` columns
+- types allowed: ` + strings.Join(in.Types, ", ") + `
+Output format:
+- First line: "<type>(optional scope): <title>"
+- Body: a short bullet summary of the merged changes`)
+	if len(in.Conflicts) > 0 {
+		b.WriteString(`, then a "Conflicts resolved:" paragraph explaining how each conflict was resolved`)
+	}
+	b.WriteString(`.
+- Output ONLY the commit message. No steps, no tables, no quotes, no extra text.
+- Do not include code fences, backticks, or explanations.
+
+Git's default merge message:
+` + in.PreparedMessage + `
+
+Merged commits:
+- ` + strings.Join(in.Commits, "\n- ") + "\n")
+	if len(in.Conflicts) > 0 {
+		b.WriteString("\nConflicted files:\n- " + strings.Join(in.Conflicts, "\n- ") + "\n")
+		b.WriteString("\nResolution (final content compared to each side of the merge):\n" + in.Resolution + "\n")
+	}
+	return b.String()
+}
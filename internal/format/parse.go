@@ -0,0 +1,116 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Footer is a git-trailer style line at the end of a commit message,
+// e.g. "Refs: PAY-482" or "BREAKING CHANGE: drop v1 API".
+type Footer struct {
+	Token string
+	Value string
+}
+
+// ConventionalCommit is a commit message parsed per the Conventional Commits spec.
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Body        string
+	Footers     []Footer
+	// Breaking is set by a "!" after the type/scope or by a BREAKING CHANGE footer.
+	Breaking bool
+}
+
+var (
+	subjectRe = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	// Footers use either "Token: value" or "Token #value" (e.g. "Closes #12").
+	footerRe     = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][\w-]*):\s(.*)$`)
+	footerHashRe = regexp.MustCompile(`^([A-Za-z][\w-]*)\s(#.*)$`)
+)
+
+// ParseConventional parses msg as a Conventional Commit. It reports false when
+// the subject line does not follow the "<type>(scope)!: description" form.
+func ParseConventional(msg string) (ConventionalCommit, bool) {
+	msg = strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n"))
+	subject, rest, _ := strings.Cut(msg, "\n")
+	m := subjectRe.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return ConventionalCommit{}, false
+	}
+	cc := ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       strings.TrimSpace(m[2]),
+		Description: strings.TrimSpace(m[4]),
+		Breaking:    m[3] == "!",
+	}
+
+	paragraphs := splitParagraphs(rest)
+	if n := len(paragraphs); n > 0 {
+		if footers, ok := parseFooters(paragraphs[n-1]); ok {
+			cc.Footers = footers
+			paragraphs = paragraphs[:n-1]
+		}
+	}
+	cc.Body = strings.Join(paragraphs, "\n\n")
+	for _, f := range cc.Footers {
+		if IsBreakingToken(f.Token) {
+			cc.Breaking = true
+		}
+	}
+	return cc, true
+}
+
+// IsBreakingToken reports whether a footer token announces a breaking change.
+func IsBreakingToken(token string) bool {
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
+
+// BreakingNotes returns the text of every BREAKING CHANGE footer, falling back
+// to the description when the breaking change was only marked with "!".
+func (c ConventionalCommit) BreakingNotes() []string {
+	var notes []string
+	for _, f := range c.Footers {
+		if IsBreakingToken(f.Token) {
+			notes = append(notes, f.Value)
+		}
+	}
+	if len(notes) == 0 && c.Breaking {
+		notes = append(notes, c.Description)
+	}
+	return notes
+}
+
+// parseFooters parses a paragraph as a trailer block. Every line must be a
+// footer or an indented continuation of the previous one.
+func parseFooters(paragraph string) ([]Footer, bool) {
+	var footers []Footer
+	for _, ln := range strings.Split(paragraph, "\n") {
+		m := footerRe.FindStringSubmatch(ln)
+		if m == nil {
+			m = footerHashRe.FindStringSubmatch(ln)
+		}
+		if m != nil {
+			footers = append(footers, Footer{Token: m[1], Value: strings.TrimSpace(m[2])})
+			continue
+		}
+		if len(footers) > 0 && (strings.HasPrefix(ln, " ") || strings.HasPrefix(ln, "\t")) {
+			last := &footers[len(footers)-1]
+			last.Value += "\n" + strings.TrimSpace(ln)
+			continue
+		}
+		return nil, false
+	}
+	return footers, len(footers) > 0
+}
+
+func splitParagraphs(s string) []string {
+	var out []string
+	for _, p := range regexp.MustCompile(`\n\s*\n`).Split(strings.TrimSpace(s), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
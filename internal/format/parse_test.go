@@ -0,0 +1,83 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConventional(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want ConventionalCommit
+		ok   bool
+	}{
+		{
+			name: "plain",
+			msg:  "feat: add login",
+			want: ConventionalCommit{Type: "feat", Description: "add login"},
+			ok:   true,
+		},
+		{
+			name: "scope and bang",
+			msg:  "Fix(api)!: drop v1",
+			want: ConventionalCommit{Type: "fix", Scope: "api", Description: "drop v1", Breaking: true},
+			ok:   true,
+		},
+		{
+			name: "breaking footer with continuation",
+			msg:  "refactor(auth): rework sessions\r\n\r\nSessions are stored server side.\r\n\r\nBREAKING CHANGE: sessions last 1 hour\r\n  instead of 24.\r\nRefs: PAY-482\r\nCloses #12\r\n",
+			want: ConventionalCommit{
+				Type:        "refactor",
+				Scope:       "auth",
+				Description: "rework sessions",
+				Body:        "Sessions are stored server side.",
+				Footers: []Footer{
+					{Token: "BREAKING CHANGE", Value: "sessions last 1 hour\ninstead of 24."},
+					{Token: "Refs", Value: "PAY-482"},
+					{Token: "Closes", Value: "#12"},
+				},
+				Breaking: true,
+			},
+			ok: true,
+		},
+		{
+			name: "hyphenated breaking token",
+			msg:  "feat: new flags\n\nBREAKING-CHANGE: --old is gone",
+			want: ConventionalCommit{
+				Type:        "feat",
+				Description: "new flags",
+				Footers:     []Footer{{Token: "BREAKING-CHANGE", Value: "--old is gone"}},
+				Breaking:    true,
+			},
+			ok: true,
+		},
+		{
+			name: "last paragraph is not a footer block",
+			msg:  "docs: explain setup\n\nSee the wiki.\nRefs: DOC-1",
+			want: ConventionalCommit{Type: "docs", Description: "explain setup", Body: "See the wiki.\nRefs: DOC-1"},
+			ok:   true,
+		},
+		{name: "not conventional", msg: "Merge branch 'main'"},
+		{name: "missing description", msg: "feat: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseConventional(tt.msg)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConventional() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBreakingNotes(t *testing.T) {
+	cc, _ := ParseConventional("feat(api)!: drop v1")
+	if got := cc.BreakingNotes(); !reflect.DeepEqual(got, []string{"drop v1"}) {
+		t.Errorf("BreakingNotes() = %q", got)
+	}
+	cc, _ = ParseConventional("feat!: drop v1\n\nBREAKING CHANGE: use /v2\nBREAKING CHANGE: new auth")
+	if got := cc.BreakingNotes(); !reflect.DeepEqual(got, []string{"use /v2", "new auth"}) {
+		t.Errorf("BreakingNotes() = %q", got)
+	}
+}
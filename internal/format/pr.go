@@ -0,0 +1,132 @@
+package format
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// DefaultPRSections are used when the repository has no pull request template.
+var DefaultPRSections = []string{"Summary", "Changes", "Testing"}
+
+type PRPromptInput struct {
+	Diff     diff.Diff // redacted and truncated
+	Commits  []string  // commit subjects, oldest first
+	Sections []string  // Markdown headings the body must use, in order
+	MaxTitle int
+}
+
+// BuildPRPrompt asks for a pull request title plus a Markdown body that
+// follows the given section headings.
+func BuildPRPrompt(in PRPromptInput) string {
+	sections := in.Sections
+	if len(sections) == 0 {
+		sections = DefaultPRSections
+	}
+	var headings []string
+	for _, s := range sections {
+		headings = append(headings, "## "+s)
+	}
+	return `Write a pull request title and description for the following branch.
+Constraints:
+- First line: the PR title, <= ` + strconv.Itoa(in.MaxTitle) + ` characters, in Conventional Commit form "<type>(optional scope): <title>"
+- Then a blank line, then the description in Markdown.
+- The description must use exactly these headings, in this order:
+` + strings.Join(headings, "\n") + `
+- Describe what changed and why; mention how the change can be tested.
+- Output ONLY the title and description. No preamble, no code fences around the whole answer.
+
+Commits:
+- ` + strings.Join(in.Commits, "\n- ") + `
+
+` + describeDiff(in.Diff) + `
+`
+}
+
+var (
+	// wholeFenceRe matches an answer wrapped in a single code fence.
+	wholeFenceRe = regexp.MustCompile("(?s)^```[a-z]*\n(.*)\n```$")
+	// titleLabelRe matches a "Title:" or "PR title:" label before the title.
+	titleLabelRe = regexp.MustCompile(`(?i)^(pr\s+)?title:\s*`)
+)
+
+// ParsePRResponse splits model output into a title and a Markdown body.
+func ParsePRResponse(out string) (title, body string) {
+	out = strings.TrimSpace(out)
+	// Unwrap a single fence around the whole answer
+	if m := wholeFenceRe.FindStringSubmatch(out); m != nil {
+		out = strings.TrimSpace(m[1])
+	}
+	first, rest, _ := strings.Cut(out, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(first, "# "))
+	title = strings.TrimSpace(titleLabelRe.ReplaceAllString(title, ""))
+	title = strings.Trim(title, "\"`")
+	return title, strings.TrimSpace(rest)
+}
+
+// TemplateSections extracts the Markdown headings of a pull request template.
+// Lines starting with '#' inside code fences (shell comments, say) and HTML
+// comments (template instructions) are not headings.
+func TemplateSections(tmpl string) []string {
+	var out []string
+	inFence, inComment := false, false
+	for _, ln := range strings.Split(tmpl, "\n") {
+		t := strings.TrimSpace(ln)
+		if inComment {
+			if _, after, ok := strings.Cut(t, "-->"); ok {
+				inComment = false
+				t = strings.TrimSpace(after)
+			} else {
+				continue
+			}
+		}
+		if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if strings.HasPrefix(t, "<!--") {
+			if !strings.Contains(t[4:], "-->") {
+				inComment = true
+			}
+			continue
+		}
+		if !strings.HasPrefix(t, "#") {
+			continue
+		}
+		h := strings.TrimSpace(strings.TrimLeft(t, "#"))
+		if h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// FallbackPR builds a plain description from commit subjects when AI fails.
+func FallbackPR(commits []string, sections []string) (title, body string) {
+	title = "chore: update branch"
+	if len(commits) == 1 {
+		title = commits[0]
+	}
+	if len(sections) == 0 {
+		sections = DefaultPRSections
+	}
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("## " + s + "\n\n")
+		if i == 0 {
+			b.WriteString(strconv.Itoa(len(commits)) + " commit(s):\n\n")
+			for _, c := range commits {
+				b.WriteString("- " + c + "\n")
+			}
+		}
+	}
+	return title, strings.TrimSpace(b.String())
+}
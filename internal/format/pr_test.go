@@ -0,0 +1,55 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTruncateTitle(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"feat: add login", 72, "feat: add login"},
+		{"feat: add login", 9, "feat: add"},
+		{"fix: naïve café", 14, "fix: naïve caf"},
+		{"docs: 日本語のドキュメント", 9, "docs: 日本語"},
+	}
+	for _, tt := range tests {
+		if got := TruncateTitle(tt.in, tt.max); got != tt.want {
+			t.Errorf("TruncateTitle(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateSections(t *testing.T) {
+	tmpl := "## Summary\n" +
+		"<!-- # Describe the change -->\n" +
+		"<!--\n" +
+		"# Checklist for reviewers\n" +
+		"-->\n" +
+		"## How to test\n" +
+		"```sh\n" +
+		"# run the suite\n" +
+		"make test\n" +
+		"```\n" +
+		"~~~\n" +
+		"# not a heading\n" +
+		"~~~\n" +
+		"### Notes\n"
+	want := []string{"Summary", "How to test", "Notes"}
+	if got := TemplateSections(tmpl); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateSections() = %q, want %q", got, want)
+	}
+}
+
+func TestParsePRResponse(t *testing.T) {
+	title, body := ParsePRResponse("```markdown\nTitle: feat: add login\n\n## Summary\nAdds login.\n```")
+	if title != "feat: add login" {
+		t.Errorf("title = %q", title)
+	}
+	if body != "## Summary\nAdds login." {
+		t.Errorf("body = %q", body)
+	}
+}
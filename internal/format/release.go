@@ -0,0 +1,23 @@
+package format
+
+// ReleaseNotesInput describes a release for the notes prompt.
+type ReleaseNotesInput struct {
+	Tag       string
+	Changelog string // deterministic changelog section for the release
+}
+
+// BuildReleaseNotesPrompt asks for user-facing release notes. The changelog
+// section is the only source of truth the model is given.
+func BuildReleaseNotesPrompt(in ReleaseNotesInput) string {
+	return `Write release notes for ` + in.Tag + ` aimed at end users of the software.
+Constraints:
+- Start with one or two sentences summarizing the release.
+- Then short Markdown sections for breaking changes (with upgrade guidance), new features and fixes; omit empty sections.
+- Explain the impact for users rather than listing internal refactors, tests or chores.
+- Use only the information in the changelog below; do not invent changes.
+- Output ONLY the release notes. No preamble, no code fences.
+
+Changelog:
+` + in.Changelog + `
+`
+}
@@ -0,0 +1,69 @@
+package format
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SplitGroup is a set of hunk numbers (1-based, as shown to the model)
+// that belong in one commit, with an optional short label.
+type SplitGroup struct {
+	Hunks []int
+	Label string
+}
+
+// BuildSplitPrompt asks the model to partition numbered hunks into logical commits.
+func BuildSplitPrompt(hunks []string) string {
+	var b strings.Builder
+	for i, h := range hunks {
+		b.WriteString("### Hunk " + strconv.Itoa(i+1) + "\n" + h + "\n")
+	}
+	return `The following staged changes should be split into several logical commits.
+Group the numbered hunks so that each group is one coherent change (a feature, a fix, a refactor, docs, ...).
+Rules:
+- Every hunk number from 1 to ` + strconv.Itoa(len(hunks)) + ` must appear in exactly one group.
+- Prefer few groups; only separate changes that are genuinely unrelated.
+Output format, one group per line and nothing else:
+<comma-separated hunk numbers>: <short label>
+Example:
+1,3,4: add retry policy
+2: fix typo in README
+
+` + b.String()
+}
+
+var splitLineRe = regexp.MustCompile(`^\s*(?:[-*]\s*)?(?:group\s*\d+\s*[:.)-]\s*)?([\d,\s]+?)\s*(?::\s*(.*))?$`)
+
+// ParseSplitGroups reads the model's grouping. It reports false unless every
+// hunk 1..n is assigned exactly once.
+func ParseSplitGroups(out string, n int) ([]SplitGroup, bool) {
+	seen := make([]bool, n+1)
+	var groups []SplitGroup
+	for _, ln := range strings.Split(out, "\n") {
+		m := splitLineRe.FindStringSubmatch(ln)
+		if m == nil {
+			continue
+		}
+		var g SplitGroup
+		for _, f := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' }) {
+			id, err := strconv.Atoi(f)
+			if err != nil || id < 1 || id > n || seen[id] {
+				return nil, false
+			}
+			seen[id] = true
+			g.Hunks = append(g.Hunks, id)
+		}
+		if len(g.Hunks) == 0 {
+			continue
+		}
+		g.Label = strings.TrimSpace(m[2])
+		groups = append(groups, g)
+	}
+	for id := 1; id <= n; id++ {
+		if !seen[id] {
+			return nil, false
+		}
+	}
+	return groups, true
+}
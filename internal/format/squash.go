@@ -0,0 +1,75 @@
+package format
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+type SquashPromptInput struct {
+	Diff     diff.Diff // net diff of the range, redacted and truncated
+	Commits  []string  // subjects of the squashed commits, oldest first
+	Breaking []string  // breaking-change notes, appended as footers afterwards
+	Types    []string
+	MaxTitle int
+	MaxBody  int
+}
+
+// BuildSquashPrompt asks for a single message that summarizes a series of
+// commits about to be squashed into one.
+func BuildSquashPrompt(in SquashPromptInput) string {
+	var b strings.Builder
+	b.WriteString(`Generate one Conventional Commit message for a squash merge of the commits below.
+Constraints:
+- title <= ` + strconv.Itoa(in.MaxTitle) + ` characters, describing the change as a whole
+- optional body lines <= ` + strconv.Itoa(in.MaxBody) + ` columns
+- types allowed: ` + strings.Join(in.Types, ", ") + `
+Output format:
+- First line: "<type>(optional scope): <title>"
+- Body: a short bullet summary of what the commits changed together; skip fixups and reverts
+  of work done within the series.
+- Output ONLY the commit message. No steps, no tables, no quotes, no extra text.
+- Do not include code fences, backticks, or explanations.
+
+Commits being squashed:
+- ` + strings.Join(in.Commits, "\n- ") + "\n")
+	if len(in.Breaking) > 0 {
+		b.WriteString("\nBreaking changes (BREAKING CHANGE footers are added automatically; do not write them):\n- " +
+			strings.Join(in.Breaking, "\n- ") + "\n")
+	}
+	b.WriteString("\n" + describeDiff(in.Diff) + "\n")
+	return b.String()
+}
+
+// KeepBreakingChanges replaces any BREAKING CHANGE footers in msg with one
+// footer per note, so breaking changes of squashed commits survive verbatim.
+// Multi-line notes are written as indented continuation lines.
+func KeepBreakingChanges(msg string, notes []string) string {
+	if len(notes) == 0 {
+		return msg
+	}
+	var kept []string
+	inBreaking := false
+	for _, ln := range strings.Split(strings.TrimRight(msg, "\n"), "\n") {
+		if m := footerRe.FindStringSubmatch(ln); m != nil && IsBreakingToken(m[1]) {
+			inBreaking = true
+			continue
+		}
+		if inBreaking && (strings.HasPrefix(ln, " ") || strings.HasPrefix(ln, "\t")) {
+			continue
+		}
+		inBreaking = false
+		kept = append(kept, ln)
+	}
+
+	var footers []string
+	for _, note := range notes {
+		lines := strings.Split(strings.TrimSpace(note), "\n")
+		for i := 1; i < len(lines); i++ {
+			lines[i] = "  " + strings.TrimSpace(lines[i])
+		}
+		footers = append(footers, "BREAKING CHANGE: "+strings.Join(lines, "\n"))
+	}
+	return AppendTrailers(strings.TrimRight(strings.Join(kept, "\n"), "\n"), footers)
+}
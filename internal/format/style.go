@@ -0,0 +1,432 @@
+package format
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Casing habits of commit descriptions.
+const (
+	CaseLower = "lower"
+	CaseUpper = "upper"
+)
+
+// Body formats of commit messages.
+const (
+	BodyNone    = "none"
+	BodyBullets = "bullets"
+	BodyProse   = "prose"
+)
+
+// StyleSample is one commit of the history a style profile is learned from.
+type StyleSample struct {
+	Message string
+	Paths   []string
+}
+
+// StyleProfile captures a repository's commit message habits, learned from
+// its history by LearnStyle.
+type StyleProfile struct {
+	// Commits is the number of commits the profile was learned from.
+	Commits int `json:"commits"`
+	// Conventional is the share of subjects in Conventional Commit form.
+	Conventional float64 `json:"conventional"`
+	// Casing is CaseLower or CaseUpper when descriptions clearly prefer one,
+	// empty when the history is mixed.
+	Casing string `json:"casing,omitempty"`
+	// ScopeUsage is the share of Conventional Commits that carry a scope.
+	ScopeUsage float64 `json:"scope_usage"`
+	// Scopes counts how often each scope was used.
+	Scopes map[string]int `json:"scopes,omitempty"`
+	// PathScopes maps directories (or top-level files) to the scope most
+	// commits touching them used.
+	PathScopes []PathScope `json:"path_scopes,omitempty"`
+	// BodyFormat is BodyBullets, BodyProse or BodyNone when most commits have no body.
+	BodyFormat string `json:"body_format"`
+	// TitleLength is the median subject length in characters.
+	TitleLength int `json:"title_length"`
+	// Examples are representative messages without trailers, newest first.
+	Examples []string `json:"examples,omitempty"`
+}
+
+// PathScope records that commits touching Path usually use Scope.
+type PathScope struct {
+	Path  string `json:"path"`
+	Scope string `json:"scope"`
+	Count int    `json:"count"`
+}
+
+// maxStyleExamples is how many examples a profile keeps; prompts use fewer.
+const maxStyleExamples = 5
+
+// LearnStyle builds a profile from samples, newest first as `git log` lists them.
+func LearnStyle(samples []StyleSample) StyleProfile {
+	p := StyleProfile{Commits: len(samples), Scopes: map[string]int{}, BodyFormat: BodyNone}
+	var (
+		conventional, scoped, cased, lower, withBody, bullets int
+		lengths                                               []int
+		dirScopes                                             = map[string]map[string]int{}
+	)
+	for _, s := range samples {
+		subject, _, _ := strings.Cut(strings.TrimSpace(s.Message), "\n")
+		lengths = append(lengths, utf8.RuneCountInString(subject))
+		if body := messageBody(s.Message); body != "" {
+			withBody++
+			if isBulletBody(body) {
+				bullets++
+			}
+		}
+
+		desc := subject
+		cc, ok := ParseConventional(s.Message)
+		if ok {
+			desc = cc.Description
+			conventional++
+		}
+		if r, _ := utf8.DecodeRuneInString(desc); unicode.IsLetter(r) && !isIdentifier(firstWord(desc)) {
+			cased++
+			if unicode.IsLower(r) {
+				lower++
+			}
+		}
+		if !ok || cc.Scope == "" {
+			continue
+		}
+		scoped++
+		p.Scopes[cc.Scope]++
+		for _, dir := range scopeDirs(s.Paths) {
+			if dirScopes[dir] == nil {
+				dirScopes[dir] = map[string]int{}
+			}
+			dirScopes[dir][cc.Scope]++
+		}
+	}
+	if len(samples) == 0 {
+		return p
+	}
+
+	p.Conventional = ratio(conventional, len(samples))
+	p.ScopeUsage = ratio(scoped, conventional)
+	switch share := ratio(lower, cased); {
+	case cased == 0:
+	case share >= 0.8:
+		p.Casing = CaseLower
+	case share <= 0.2:
+		p.Casing = CaseUpper
+	}
+	switch {
+	case withBody*5 < len(samples):
+		p.BodyFormat = BodyNone
+	case bullets*2 > withBody:
+		p.BodyFormat = BodyBullets
+	default:
+		p.BodyFormat = BodyProse
+	}
+	sort.Ints(lengths)
+	p.TitleLength = lengths[len(lengths)/2]
+
+	for dir, counts := range dirScopes {
+		best, bestN, total := "", 0, 0
+		for scope, n := range counts {
+			total += n
+			if n > bestN || (n == bestN && scope < best) {
+				best, bestN = scope, n
+			}
+		}
+		if bestN >= 2 && bestN*2 > total {
+			p.PathScopes = append(p.PathScopes, PathScope{Path: dir, Scope: best, Count: bestN})
+		}
+	}
+	sort.Slice(p.PathScopes, func(i, j int) bool { return p.PathScopes[i].Path < p.PathScopes[j].Path })
+
+	p.Examples = pickExamples(samples, p)
+	return p
+}
+
+// ScopeFor returns the scope the repository usually uses for changes to
+// paths, or "" when most of the paths have no learned scope.
+func (p *StyleProfile) ScopeFor(paths []string) string {
+	if p == nil || len(paths) == 0 {
+		return ""
+	}
+	votes := map[string]int{}
+	for _, path := range paths {
+		best := -1
+		for i, r := range p.PathScopes {
+			if path != r.Path && !strings.HasPrefix(path, r.Path+"/") {
+				continue
+			}
+			if best < 0 || len(r.Path) > len(p.PathScopes[best].Path) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			votes[p.PathScopes[best].Scope]++
+		}
+	}
+	scope, n := "", 0
+	for s, v := range votes {
+		if v > n || (v == n && s < scope) {
+			scope, n = s, v
+		}
+	}
+	if n*2 <= len(paths) {
+		return ""
+	}
+	return scope
+}
+
+// promptHint describes the profile for a prompt about a change to paths.
+func (p *StyleProfile) promptHint(paths []string) string {
+	var rules []string
+	switch p.Casing {
+	case CaseLower:
+		rules = append(rules, "descriptions start with a lowercase letter")
+	case CaseUpper:
+		rules = append(rules, "descriptions start with a capital letter")
+	}
+	if p.Conventional > 0 {
+		switch {
+		case p.ScopeUsage < 0.1:
+			rules = append(rules, "scopes are not used")
+		case len(p.Scopes) > 0:
+			rule := "known scopes: " + strings.Join(p.topScopes(10), ", ")
+			if scope := p.ScopeFor(paths); scope != "" {
+				rule = `use the scope "` + scope + `" for these files; ` + rule
+			}
+			rules = append(rules, rule)
+		}
+	}
+	switch p.BodyFormat {
+	case BodyNone:
+		rules = append(rules, "most commits have no body; add one only when the title cannot explain the change")
+	case BodyBullets:
+		rules = append(rules, `bodies are bullet lists ("- ...")`)
+	case BodyProse:
+		rules = append(rules, "bodies are short prose paragraphs")
+	}
+	if p.TitleLength > 0 {
+		rules = append(rules, "typical title length: "+strconv.Itoa(p.TitleLength)+" characters")
+	}
+
+	var b strings.Builder
+	b.WriteString("\nFollow this repository's commit style:\n- " + strings.Join(rules, "\n- ") + "\n")
+	if examples := p.Examples; len(examples) > 0 {
+		if len(examples) > 3 {
+			examples = examples[:3]
+		}
+		b.WriteString("Examples of recent commit messages:\n")
+		for _, ex := range examples {
+			b.WriteString("---\n" + ex + "\n")
+		}
+		b.WriteString("---\n")
+	}
+	return b.String()
+}
+
+// applyTitle rewrites a Conventional Commit title to the profile's casing
+// and scope habits; suggested is the scope learned for the changed paths.
+func (p *StyleProfile) applyTitle(title, suggested string) string {
+	m := subjectRe.FindStringSubmatch(title)
+	if m == nil {
+		return title
+	}
+	ty, scope, bang, desc := m[1], strings.TrimSpace(m[2]), m[3], m[4]
+	if p.Conventional > 0 {
+		switch {
+		case p.ScopeUsage < 0.1:
+			scope = ""
+		case scope != "":
+			if known := p.knownScope(scope); known != "" {
+				scope = known
+			} else if suggested != "" {
+				scope = suggested
+			}
+		case p.ScopeUsage >= 0.6:
+			scope = suggested
+		}
+	}
+	desc = applyCasing(desc, p.Casing)
+	if scope != "" {
+		ty += "(" + scope + ")"
+	}
+	return ty + bang + ": " + desc
+}
+
+// knownScope returns the learned spelling of scope, matched case-insensitively.
+func (p *StyleProfile) knownScope(scope string) string {
+	for s := range p.Scopes {
+		if strings.EqualFold(s, scope) {
+			return s
+		}
+	}
+	return ""
+}
+
+// topScopes returns up to n scopes, most used first.
+func (p *StyleProfile) topScopes(n int) []string {
+	scopes := make([]string, 0, len(p.Scopes))
+	for s := range p.Scopes {
+		scopes = append(scopes, s)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if p.Scopes[scopes[i]] != p.Scopes[scopes[j]] {
+			return p.Scopes[scopes[i]] > p.Scopes[scopes[j]]
+		}
+		return scopes[i] < scopes[j]
+	})
+	if len(scopes) > n {
+		scopes = scopes[:n]
+	}
+	return scopes
+}
+
+// pickExamples chooses Conventional Commits whose title length and body
+// format are typical for the profile, preferring a variety of types.
+func pickExamples(samples []StyleSample, p StyleProfile) []string {
+	type candidate struct {
+		ty, msg string
+	}
+	var candidates []candidate
+	for _, s := range samples {
+		cc, ok := ParseConventional(s.Message)
+		if !ok {
+			continue
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(s.Message), "\n")
+		n := utf8.RuneCountInString(subject)
+		if n > 72 || n < p.TitleLength/2 || n > p.TitleLength*3/2+1 {
+			continue
+		}
+		body := messageBody(s.Message)
+		switch p.BodyFormat {
+		case BodyNone:
+			if body != "" {
+				continue
+			}
+		case BodyBullets:
+			if body == "" || !isBulletBody(body) {
+				continue
+			}
+		case BodyProse:
+			if body == "" || isBulletBody(body) {
+				continue
+			}
+		}
+		msg := subject
+		if body != "" {
+			msg += "\n\n" + body
+		}
+		candidates = append(candidates, candidate{cc.Type, msg})
+	}
+
+	var out []string
+	used := map[int]bool{}
+	types := map[string]bool{}
+	for i, c := range candidates {
+		if len(out) < maxStyleExamples && !types[c.ty] {
+			types[c.ty], used[i] = true, true
+			out = append(out, c.msg)
+		}
+	}
+	for i, c := range candidates {
+		if len(out) < maxStyleExamples && !used[i] {
+			out = append(out, c.msg)
+		}
+	}
+	return out
+}
+
+// messageBody returns the paragraphs after the subject, without trailers.
+func messageBody(msg string) string {
+	_, rest, _ := strings.Cut(strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n")), "\n")
+	paragraphs := splitParagraphs(rest)
+	if n := len(paragraphs); n > 0 {
+		if _, ok := parseFooters(paragraphs[n-1]); ok {
+			paragraphs = paragraphs[:n-1]
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// isBulletBody reports whether most non-empty body lines are list items.
+func isBulletBody(body string) bool {
+	items, lines := 0, 0
+	for _, ln := range strings.Split(body, "\n") {
+		t := strings.TrimSpace(ln)
+		if t == "" {
+			continue
+		}
+		lines++
+		if strings.HasPrefix(t, "- ") || strings.HasPrefix(t, "* ") {
+			items++
+		}
+	}
+	return items > 0 && items*2 >= lines
+}
+
+// scopeDirs returns the directories (up to two levels deep) that paths live
+// in; top-level files stand for themselves.
+func scopeDirs(paths []string) []string {
+	seen := map[string]bool{}
+	var dirs []string
+	add := func(d string) {
+		if !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	for _, path := range paths {
+		parts := strings.Split(path, "/")
+		if len(parts) == 1 {
+			add(path)
+			continue
+		}
+		for i := 1; i < len(parts) && i <= 2; i++ {
+			add(strings.Join(parts[:i], "/"))
+		}
+	}
+	return dirs
+}
+
+func applyCasing(desc, casing string) string {
+	if casing == "" || desc == "" || isIdentifier(firstWord(desc)) {
+		return desc
+	}
+	r, size := utf8.DecodeRuneInString(desc)
+	switch casing {
+	case CaseLower:
+		r = unicode.ToLower(r)
+	case CaseUpper:
+		r = unicode.ToUpper(r)
+	}
+	return string(r) + desc[size:]
+}
+
+// isIdentifier reports words whose casing carries meaning, such as acronyms,
+// camelCase names, paths and file names.
+func isIdentifier(w string) bool {
+	for i, r := range w {
+		if !unicode.IsLetter(r) || (i > 0 && unicode.IsUpper(r)) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return strings.TrimRight(f[0], ".,:;!?")
+	}
+	return ""
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,183 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLearnStyle(t *testing.T) {
+	samples := []StyleSample{
+		{Message: "feat(api): add pagination\n\n- cursor based\n- default 50", Paths: []string{"internal/api/list.go"}},
+		{Message: "fix(api): handle empty body", Paths: []string{"internal/api/handler.go"}},
+		{Message: "fix(cli): quote paths\n\n- spaces broke the hook", Paths: []string{"internal/cli/hook.go"}},
+		{Message: "docs: describe pagination\n\nRefs: PAY-1", Paths: []string{"README.md"}},
+		{Message: "feat(api): add filters\n\n- by date\n- by owner", Paths: []string{"internal/api/filter.go", "README.md"}},
+		{Message: "Merge branch 'topic'", Paths: nil},
+		{Message: "refactor(cli): split flags\n\n- one file per command", Paths: []string{"internal/cli/flags.go"}},
+	}
+	got := LearnStyle(samples)
+	want := StyleProfile{
+		Commits:      7,
+		Conventional: 6.0 / 7,
+		Casing:       CaseLower,
+		ScopeUsage:   5.0 / 6,
+		Scopes:       map[string]int{"api": 3, "cli": 2},
+		PathScopes: []PathScope{
+			{Path: "internal", Scope: "api", Count: 3},
+			{Path: "internal/api", Scope: "api", Count: 3},
+			{Path: "internal/cli", Scope: "cli", Count: 2},
+		},
+		BodyFormat:  BodyBullets,
+		TitleLength: 25,
+		Examples: []string{
+			"feat(api): add pagination\n\n- cursor based\n- default 50",
+			"fix(cli): quote paths\n\n- spaces broke the hook",
+			"refactor(cli): split flags\n\n- one file per command",
+			"feat(api): add filters\n\n- by date\n- by owner",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LearnStyle() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := LearnStyle(nil); got.Commits != 0 || got.BodyFormat != BodyNone || got.Casing != "" {
+		t.Errorf("LearnStyle(nil) = %+v", got)
+	}
+}
+
+func TestLearnStyleCasing(t *testing.T) {
+	tests := []struct {
+		subjects []string
+		want     string
+	}{
+		{[]string{"feat: Add a", "fix: Handle b", "docs: Describe c"}, CaseUpper},
+		{[]string{"feat: add a", "fix: handle b", "docs: describe c"}, CaseLower},
+		{[]string{"feat: Add a", "fix: handle b"}, ""},
+		// identifiers and non-letters say nothing about casing
+		{[]string{"feat: add a", "fix: README typo", "fix: getUser nil check", "docs: 2 typos"}, CaseLower},
+	}
+	for _, tt := range tests {
+		var samples []StyleSample
+		for _, s := range tt.subjects {
+			samples = append(samples, StyleSample{Message: s})
+		}
+		if got := LearnStyle(samples).Casing; got != tt.want {
+			t.Errorf("LearnStyle(%q).Casing = %q, want %q", tt.subjects, got, tt.want)
+		}
+	}
+}
+
+func TestScopeFor(t *testing.T) {
+	p := &StyleProfile{PathScopes: []PathScope{
+		{Path: "internal", Scope: "core", Count: 5},
+		{Path: "internal/api", Scope: "api", Count: 3},
+		{Path: "internal/cli", Scope: "cli", Count: 2},
+		{Path: "go.mod", Scope: "deps", Count: 2},
+	}}
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"internal/api/list.go"}, "api"},
+		{[]string{"internal/api/list.go", "internal/api/filter.go", "README.md"}, "api"},
+		{[]string{"internal/api/list.go", "internal/cli/hook.go"}, ""},
+		{[]string{"internal/api/list.go", "internal/cli/hook.go", "internal/cli/flags.go"}, "cli"},
+		{[]string{"internal/format/style.go"}, "core"},
+		{[]string{"internal-tools/x.go"}, ""},
+		{[]string{"go.mod"}, "deps"},
+		{[]string{"README.md", "docs/x.md"}, ""},
+	}
+	for _, tt := range tests {
+		if got := p.ScopeFor(tt.paths); got != tt.want {
+			t.Errorf("ScopeFor(%q) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+	var nilProfile *StyleProfile
+	if got := nilProfile.ScopeFor([]string{"a"}); got != "" {
+		t.Errorf("nil ScopeFor() = %q", got)
+	}
+}
+
+func TestApplyTitle(t *testing.T) {
+	scoped := &StyleProfile{Conventional: 1, ScopeUsage: 0.8, Scopes: map[string]int{"API": 4, "cli": 2}, Casing: CaseLower}
+	optional := &StyleProfile{Conventional: 1, ScopeUsage: 0.3, Scopes: map[string]int{"api": 1}, Casing: CaseUpper}
+	unscoped := &StyleProfile{Conventional: 1, ScopeUsage: 0.05}
+	freeform := &StyleProfile{Conventional: 0, ScopeUsage: 0}
+	tests := []struct {
+		name      string
+		p         *StyleProfile
+		title     string
+		suggested string
+		want      string
+	}{
+		{"known scope respelled", scoped, "feat(api): Add paging", "cli", "feat(API): add paging"},
+		{"unknown scope replaced", scoped, "feat(server): add paging", "cli", "feat(cli): add paging"},
+		{"unknown scope kept without suggestion", scoped, "feat(server): add paging", "", "feat(server): add paging"},
+		{"missing scope added", scoped, "fix: handle nil", "cli", "fix(cli): handle nil"},
+		{"breaking keeps bang", scoped, "feat!: Drop v1", "API", "feat(API)!: drop v1"},
+		{"optional scope not added", optional, "fix: handle nil", "api", "fix: Handle nil"},
+		{"scopes dropped", unscoped, "fix(api)!: handle nil", "api", "fix!: handle nil"},
+		{"freeform history leaves scope", freeform, "fix(api): handle nil", "", "fix(api): handle nil"},
+		{"identifier keeps casing", scoped, "fix(cli): README typo", "", "fix(cli): README typo"},
+		{"not conventional", scoped, "Update things", "cli", "Update things"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.applyTitle(tt.title, tt.suggested); got != tt.want {
+				t.Errorf("applyTitle(%q, %q) = %q, want %q", tt.title, tt.suggested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCasing(t *testing.T) {
+	tests := []struct {
+		desc, casing, want string
+	}{
+		{"Add paging", CaseLower, "add paging"},
+		{"add paging", CaseUpper, "Add paging"},
+		{"Add paging", "", "Add paging"},
+		{"Über alles", CaseLower, "über alles"},
+		{"README typo", CaseLower, "README typo"},
+		{"getUser nil check", CaseUpper, "getUser nil check"},
+		{"go.mod bump", CaseUpper, "go.mod bump"},
+		{"", CaseUpper, ""},
+	}
+	for _, tt := range tests {
+		if got := applyCasing(tt.desc, tt.casing); got != tt.want {
+			t.Errorf("applyCasing(%q, %q) = %q, want %q", tt.desc, tt.casing, got, tt.want)
+		}
+	}
+}
+
+func TestPickExamples(t *testing.T) {
+	samples := []StyleSample{
+		{Message: "feat: add a"},
+		{Message: "feat: add b"},
+		{Message: "fix: handle c\n\nProse body."},
+		{Message: "Update d"},
+		{Message: "docs: describe e\n\nRefs: PAY-1"},
+		{Message: "chore: " + strings.Repeat("x", 70)},
+		{Message: "ci: x"}, // too short
+		{Message: "test: cover g"},
+		{Message: "perf: speed up h"},
+		{Message: "style: format i"},
+	}
+	p := StyleProfile{BodyFormat: BodyNone, TitleLength: 12}
+	got := pickExamples(samples, p)
+	want := []string{"feat: add a", "docs: describe e", "test: cover g", "perf: speed up h", "style: format i"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pickExamples(BodyNone) = %q, want %q", got, want)
+	}
+
+	p.BodyFormat = BodyProse
+	if got, want := pickExamples(samples, p), []string{"fix: handle c\n\nProse body."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pickExamples(BodyProse) = %q, want %q", got, want)
+	}
+	p.BodyFormat = BodyBullets
+	if got := pickExamples(samples, p); got != nil {
+		t.Errorf("pickExamples(BodyBullets) = %q, want none", got)
+	}
+}
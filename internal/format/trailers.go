@@ -0,0 +1,58 @@
+package format
+
+import "strings"
+
+// AppendTrailers adds trailer lines ("Token: value") to msg in the layout
+// `git interpret-trailers` produces: joined to an existing trailer block at
+// the end of the message, or as a new paragraph. Trailers already present
+// (case-insensitively) are not repeated.
+func AppendTrailers(msg string, trailers []string) string {
+	msg = strings.TrimRight(msg, "\n")
+	existing := map[string]bool{}
+	for _, ln := range strings.Split(msg, "\n") {
+		existing[strings.ToLower(strings.TrimSpace(ln))] = true
+	}
+	var add []string
+	for _, t := range trailers {
+		t = strings.TrimSpace(t)
+		if t == "" || existing[strings.ToLower(t)] {
+			continue
+		}
+		existing[strings.ToLower(t)] = true
+		add = append(add, t)
+	}
+	if len(add) == 0 {
+		return msg
+	}
+
+	_, rest, hasBody := strings.Cut(msg, "\n")
+	if hasBody {
+		paragraphs := splitParagraphs(rest)
+		if n := len(paragraphs); n > 0 {
+			if _, ok := parseFooters(paragraphs[n-1]); ok {
+				return msg + "\n" + strings.Join(add, "\n")
+			}
+		}
+	}
+	return msg + "\n\n" + strings.Join(add, "\n")
+}
+
+// AppendCherryPickLine records the origin of a cherry-picked commit the way
+// `git cherry-pick -x` does, unless the message already carries it.
+func AppendCherryPickLine(msg, commit string) string {
+	msg = strings.TrimRight(msg, "\n")
+	line := "(cherry picked from commit " + commit + ")"
+	if strings.Contains(msg, line) {
+		return msg
+	}
+	_, rest, hasBody := strings.Cut(msg, "\n")
+	if hasBody {
+		paragraphs := splitParagraphs(rest)
+		if n := len(paragraphs); n > 0 {
+			if _, ok := parseFooters(paragraphs[n-1]); ok {
+				return msg + "\n" + line
+			}
+		}
+	}
+	return msg + "\n\n" + line
+}
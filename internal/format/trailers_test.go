@@ -0,0 +1,50 @@
+package format
+
+import "testing"
+
+func TestAppendTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		trailers []string
+		want     string
+	}{
+		{
+			name:     "subject only",
+			msg:      "feat: add login\n",
+			trailers: []string{"Refs: PAY-1"},
+			want:     "feat: add login\n\nRefs: PAY-1",
+		},
+		{
+			name:     "after a body",
+			msg:      "feat: add login\n\nAdds the login form.",
+			trailers: []string{"Refs: PAY-1", "Co-authored-by: Ana <ana@example.com>"},
+			want:     "feat: add login\n\nAdds the login form.\n\nRefs: PAY-1\nCo-authored-by: Ana <ana@example.com>",
+		},
+		{
+			name:     "joins an existing trailer block",
+			msg:      "fix: crash\n\nGuard against nil.\n\nSigned-off-by: Bo <bo@example.com>",
+			trailers: []string{"Refs: PAY-2"},
+			want:     "fix: crash\n\nGuard against nil.\n\nSigned-off-by: Bo <bo@example.com>\nRefs: PAY-2",
+		},
+		{
+			name:     "skips duplicates case-insensitively",
+			msg:      "fix: crash\n\nrefs: pay-2",
+			trailers: []string{"Refs: PAY-2", " ", "Refs: PAY-3", "Refs: PAY-3"},
+			want:     "fix: crash\n\nrefs: pay-2\nRefs: PAY-3",
+		},
+		{
+			name:     "nothing to add",
+			msg:      "chore: tidy\n\n",
+			trailers: nil,
+			want:     "chore: tidy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AppendTrailers(tt.msg, tt.trailers); got != tt.want {
+				t.Errorf("AppendTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// runDiff runs a diff-producing git subcommand (diff, diff-tree) twice, once for
+// the patch and once with -z --numstat for exact paths and line counts, and
+// combines the two into a diff.Diff. args[0] is the subcommand.
+func runDiff(ctx context.Context, args ...string) (diff.Diff, error) {
+	with := func(flags ...string) []string {
+		return append(append([]string{args[0]}, flags...), args[1:]...)
+	}
+	patch, err := output(ctx, with("-p", "--no-color")...)
+	if err != nil {
+		return diff.Diff{}, err
+	}
+	numstat, err := output(ctx, with("--numstat", "-z")...)
+	if err != nil {
+		return diff.Diff{}, err
+	}
+	d := diff.ParseDiff(patch, numstat)
+	d.Source.Args = args
+	return d, nil
+}
+
+// ContextOptions widens the unchanged code shown around each hunk.
+type ContextOptions struct {
+	Function bool // the whole enclosing function (--function-context)
+	Lines    int  // -U<n>; 0 keeps git's default of three lines
+}
+
+// IsZero reports whether o asks for git's default context.
+func (o ContextOptions) IsZero() bool {
+	return !o.Function && o.Lines <= 0
+}
+
+func (o ContextOptions) flags() []string {
+	var flags []string
+	if o.Function {
+		flags = append(flags, "--function-context")
+	}
+	if o.Lines > 0 {
+		flags = append(flags, "-U"+strconv.Itoa(o.Lines))
+	}
+	return flags
+}
+
+// ErrNotFromRepository is returned for diffs that were parsed from a patch
+// rather than produced by git in this repository.
+var ErrNotFromRepository = errors.New("the diff was not produced from this repository")
+
+// WithContext runs the git command that produced d again with wider context.
+func WithContext(ctx context.Context, d diff.Diff, o ContextOptions) (diff.Diff, error) {
+	src := d.Source
+	if len(src.Args) == 0 {
+		return d, ErrNotFromRepository
+	}
+	args := append(append([]string{src.Args[0]}, o.flags()...), src.Args[1:]...)
+	wide, err := runDiff(ctx, args...)
+	if err != nil {
+		return d, err
+	}
+	wide.Source.Post = src.Post
+	return wide, nil
+}
+
+// PostImage returns the content of path on the new side of d: the index for
+// staged diffs, the target revision otherwise.
+func PostImage(ctx context.Context, d diff.Diff, path string) (string, error) {
+	if d.Source.Post == "" {
+		return "", ErrNotFromRepository
+	}
+	return output(ctx, "cat-file", "blob", d.Source.Post+path)
+}
+
+// withPost records where the new side of d can be read from.
+func withPost(d diff.Diff, err error, post string) (diff.Diff, error) {
+	d.Source.Post = post
+	return d, err
+}
+
+// output runs git and returns its untrimmed stdout.
+func output(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v\n%s", args[0], err, stderr.String())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// longFunc is a Go file whose function body is long enough that a change at
+// its end is more than three lines away from the signature.
+func longFunc(last string) string {
+	var b strings.Builder
+	b.WriteString("package main\n\nfunc handler() {\n")
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&b, "\tstep%d()\n", i)
+	}
+	b.WriteString("\t" + last + "\n}\n")
+	return b.String()
+}
+
+func TestWithContext(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "main.go", longFunc("return"), "feat: add handler")
+	writeFile(t, "main.go", longFunc("done()"))
+	gitT(t, "add", "main.go")
+
+	d, err := GetStagedDiff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opt  ContextOptions
+		want []string // context lines expected in the patch
+		not  []string
+	}{
+		{name: "default", want: []string{"\n \tstep10()\n"}, not: []string{"\n func handler() {\n", "\n \tstep9()\n"}},
+		{name: "function", opt: ContextOptions{Function: true}, want: []string{"\n func handler() {\n", "\n \tstep1()\n"}},
+		{name: "lines", opt: ContextOptions{Lines: 5}, want: []string{"\n \tstep8()\n"}, not: []string{"\n \tstep7()\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wide, err := WithContext(ctx, d, tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patch := wide.String()
+			for _, s := range tt.want {
+				if !strings.Contains(patch, s) {
+					t.Errorf("patch does not contain %q:\n%s", s, patch)
+				}
+			}
+			for _, s := range tt.not {
+				if strings.Contains(patch, s) {
+					t.Errorf("patch contains %q:\n%s", s, patch)
+				}
+			}
+			if wide.Source.Post != d.Source.Post {
+				t.Errorf("Source.Post = %q, want %q", wide.Source.Post, d.Source.Post)
+			}
+		})
+	}
+
+	if _, err := WithContext(ctx, diff.ParseDiff(d.String(), ""), ContextOptions{Function: true}); !errors.Is(err, ErrNotFromRepository) {
+		t.Errorf("WithContext() on a parsed patch = %v, want ErrNotFromRepository", err)
+	}
+}
+
+func TestPostImage(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "one\n", "feat: add a")
+	commitFile(t, "a.txt", "two\n", "fix: a")
+	writeFile(t, "a.txt", "staged\n")
+	gitT(t, "add", "a.txt")
+	writeFile(t, "a.txt", "working tree\n")
+
+	staged, err := GetStagedDiff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := CommitDiff(ctx, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revs, err := DiffRevs(ctx, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tt := range map[string]struct {
+		d    diff.Diff
+		want string
+	}{
+		"index":  {d: staged, want: "staged\n"},
+		"commit": {d: commit, want: "one\n"},
+		"range":  {d: revs, want: "two\n"},
+	} {
+		if got, err := PostImage(ctx, tt.d, "a.txt"); err != nil || got != tt.want {
+			t.Errorf("PostImage() of the %s diff = %q, %v, want %q", name, got, err, tt.want)
+		}
+	}
+	if _, err := PostImage(ctx, diff.ParseDiff(staged.String(), ""), "a.txt"); !errors.Is(err, ErrNotFromRepository) {
+		t.Errorf("PostImage() of a parsed patch = %v, want ErrNotFromRepository", err)
+	}
+}
@@ -6,30 +6,142 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
 )
 
-// GetStagedDiff returns the staged diff (what would be committed).
-func GetStagedDiff(ctx context.Context) (string, error) {
+// GetStagedDiff returns the staged diff (what would be committed),
+// optionally limited to pathspecs.
+func GetStagedDiff(ctx context.Context, paths ...string) (diff.Diff, error) {
 	// --staged ensures only staged changes
-	cmd := exec.CommandContext(ctx, "git", "diff", "--staged", "--no-color")
+	d, err := runDiff(ctx, append([]string{"diff", "--staged", "--"}, paths...)...)
+	return withPost(d, err, ":")
+}
+
+// GetStagedPatch is GetStagedDiff with binary files as "GIT binary patch"
+// data instead of "Binary files differ", so that parts of it can be staged
+// again with ApplyCached. Not meant for prompts.
+func GetStagedPatch(ctx context.Context) (diff.Diff, error) {
+	d, err := runDiff(ctx, "diff", "--staged", "--binary", "--")
+	return withPost(d, err, ":")
+}
+
+// CommitOptions are the `git commit` flags gessage exposes.
+type CommitOptions struct {
+	SignOff  bool   // -s: add a Signed-off-by trailer (DCO)
+	GPGSign  bool   // -S: GPG-sign the commit
+	NoVerify bool   // --no-verify: skip pre-commit and commit-msg hooks
+	Author   string // --author="Name <email>"
+	// Extra arguments are appended verbatim, e.g. everything after `--` on the command line.
+	Extra []string
+	// Paths limits the commit to these pathspecs (`git commit -- <paths>`),
+	// leaving other staged changes in the index.
+	Paths []string
+}
+
+func (o CommitOptions) args() []string {
+	var args []string
+	if o.SignOff {
+		args = append(args, "--signoff")
+	}
+	if o.GPGSign {
+		args = append(args, "--gpg-sign")
+	}
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if o.Author != "" {
+		args = append(args, "--author="+o.Author)
+	}
+	args = append(args, o.Extra...)
+	if len(o.Paths) > 0 {
+		args = append(append(args, "--"), o.Paths...)
+	}
+	return args
+}
+
+// CommitWithMessage pipes the message to `git commit -F -`
+func CommitWithMessage(ctx context.Context, msg string, opts CommitOptions) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"commit", "-F", "-"}, opts.args()...)...)
+	cmd.Stdin = strings.NewReader(msg)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &out
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("git diff failed: %v\n%s", err, out.String())
+		return fmt.Errorf("git commit failed: %v\n%s", err, out.String())
 	}
-	return out.String(), nil
+	return nil
 }
 
-// CommitWithMessage pipes the message to `git commit -F -`
-func CommitWithMessage(ctx context.Context, msg string) error {
-	cmd := exec.CommandContext(ctx, "git", "commit", "-F", "-")
+// AddTracked stages modifications and deletions of tracked files, like
+// `git commit -a`, optionally limited to pathspecs.
+func AddTracked(ctx context.Context, paths ...string) error {
+	_, err := run(ctx, append([]string{"add", "--update", "--"}, paths...)...)
+	return err
+}
+
+// AddAll stages every change including untracked files (honouring
+// .gitignore), optionally limited to pathspecs.
+func AddAll(ctx context.Context, paths ...string) error {
+	_, err := run(ctx, append([]string{"add", "--all", "--"}, paths...)...)
+	return err
+}
+
+// run executes git with args and returns trimmed stdout.
+// On failure the error carries the subcommand name and git's stderr.
+func run(ctx context.Context, args ...string) (string, error) {
+	return runIn(ctx, "", args...)
+}
+
+// runIn is run with the working directory set to dir ("" for the current one).
+func runIn(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v\n%s", args[0], err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GetAmendDiff returns the diff an amended HEAD would contain: HEAD's parent
+// (or the empty tree for a root commit) against the index, so it covers
+// HEAD's own changes plus whatever is staged on top.
+func GetAmendDiff(ctx context.Context) (diff.Diff, error) {
+	base, err := run(ctx, "rev-parse", "--verify", "--quiet", "HEAD~1")
+	if err != nil || base == "" {
+		if _, err := run(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+			return diff.Diff{}, fmt.Errorf("nothing to amend: the repository has no commits yet")
+		}
+		if base, err = emptyTree(ctx); err != nil {
+			return diff.Diff{}, err
+		}
+	}
+	d, err := runDiff(ctx, "diff", "--cached", base)
+	return withPost(d, err, ":")
+}
+
+// HeadMessage returns the full message of the HEAD commit.
+func HeadMessage(ctx context.Context) (string, error) {
+	return run(ctx, "log", "-1", "--format=%B", "HEAD")
+}
+
+// AmendWithMessage pipes the message to `git commit --amend -F -`
+func AmendWithMessage(ctx context.Context, msg string, opts CommitOptions) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"commit", "--amend", "-F", "-"}, opts.args()...)...)
 	cmd.Stdin = strings.NewReader(msg)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &out
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git commit failed: %v\n%s", err, out.String())
+		return fmt.Errorf("git commit --amend failed: %v\n%s", err, out.String())
 	}
 	return nil
 }
+
+// emptyTree returns the id of the empty tree in the repository's hash format.
+func emptyTree(ctx context.Context) (string, error) {
+	return run(ctx, "hash-object", "-t", "tree", "--stdin")
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo creates an empty repository in a temporary directory, isolated
+// from the user's git config, and makes it the working directory.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Chdir(dir)
+	gitT(t, "init", "-q", "-b", "main")
+	return dir
+}
+
+// gitT runs git in the working directory and returns its trimmed output.
+func gitT(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeFile writes content to path (relative to the working directory),
+// creating parent directories.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commitFile writes path and commits it with msg.
+func commitFile(t *testing.T, path, content, msg string) {
+	t.Helper()
+	writeFile(t, path, content)
+	gitT(t, "add", path)
+	gitT(t, "commit", "-q", "-m", msg)
+}
+
+func TestCreateAnnotatedTagKeepsHeadings(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: first")
+	notes := "v1.0.0\n\n## Features\n\n- first\n\n### Fixes\n\n- none\n"
+	if err := CreateAnnotatedTag(context.Background(), "v1.0.0", notes); err != nil {
+		t.Fatal(err)
+	}
+	got := gitT(t, "tag", "-l", "--format=%(contents)", "v1.0.0")
+	if got != strings.TrimSpace(notes) {
+		t.Errorf("tag message = %q, want %q", got, notes)
+	}
+}
+
+func TestCommitWithMessagePaths(t *testing.T) {
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: first")
+	writeFile(t, "a.txt", "a2\n")
+	writeFile(t, "b.txt", "b\n")
+	gitT(t, "add", "a.txt", "b.txt")
+
+	opts := CommitOptions{SignOff: true, Extra: []string{"--date=2001-02-03T04:05:06Z"}, Paths: []string{"a.txt"}}
+	if err := CommitWithMessage(context.Background(), "fix: only a", opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitT(t, "show", "--name-only", "--format=", "HEAD"); got != "a.txt" {
+		t.Errorf("committed files = %q, want a.txt", got)
+	}
+	if got := gitT(t, "diff", "--staged", "--name-only"); got != "b.txt" {
+		t.Errorf("still staged = %q, want b.txt", got)
+	}
+	if got := gitT(t, "log", "-1", "--format=%ad", "--date=iso-strict"); !strings.HasPrefix(got, "2001-02-03") {
+		t.Errorf("author date = %q, want the --date passed through", got)
+	}
+	if got := gitT(t, "log", "-1", "--format=%B"); got != "fix: only a\n\nSigned-off-by: Test <test@example.com>" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestGetAmendDiff(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	if _, err := GetAmendDiff(ctx); err == nil || !strings.Contains(err.Error(), "no commits yet") {
+		t.Errorf("GetAmendDiff() without commits = %v, want an error", err)
+	}
+
+	// A root commit is compared with the empty tree
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	writeFile(t, "b.txt", "b\n")
+	gitT(t, "add", "b.txt")
+	d, err := GetAmendDiff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(d.Paths(), " "); got != "a.txt b.txt" {
+		t.Errorf("GetAmendDiff() on the root commit covers %q, want a.txt b.txt", got)
+	}
+
+	// Otherwise HEAD's parent is the base, so HEAD's own changes are included
+	gitT(t, "commit", "-q", "-m", "feat: add b")
+	writeFile(t, "a.txt", "a2\n")
+	writeFile(t, "c.txt", "c\n")
+	gitT(t, "add", "c.txt")
+	if d, err = GetAmendDiff(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(d.Paths(), " "); got != "b.txt c.txt" {
+		t.Errorf("GetAmendDiff() covers %q, want b.txt c.txt (unstaged a.txt left out)", got)
+	}
+	if post, err := PostImage(ctx, d, "c.txt"); err != nil || post != "c\n" {
+		t.Errorf("PostImage(c.txt) = %q, %v, want the staged content", post, err)
+	}
+}
+
+func TestAmendWithMessage(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	commitFile(t, "b.txt", "b\n", "wip")
+	parent := gitT(t, "rev-parse", "HEAD~1")
+	writeFile(t, "c.txt", "c\n")
+	gitT(t, "add", "c.txt")
+
+	if msg, err := HeadMessage(ctx); err != nil || msg != "wip" {
+		t.Fatalf("HeadMessage() = %q, %v", msg, err)
+	}
+	if err := AmendWithMessage(ctx, "feat: add b and c\n\nBoth files.", CommitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := HeadMessage(ctx); msg != "feat: add b and c\n\nBoth files." {
+		t.Errorf("amended message = %q", msg)
+	}
+	if got := gitT(t, "rev-parse", "HEAD~1"); got != parent {
+		t.Errorf("HEAD~1 = %s, want the original parent %s", got, parent)
+	}
+	if got := gitT(t, "show", "--name-only", "--format=", "HEAD"); got != "b.txt\nc.txt" {
+		t.Errorf("amended commit changes %q, want b.txt and c.txt", got)
+	}
+	if got := gitT(t, "rev-list", "--count", "HEAD"); got != "2" {
+		t.Errorf("%s commits after amending, want 2", got)
+	}
+}
@@ -0,0 +1,23 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// HooksDir returns the directory git runs hooks from, honoring core.hooksPath.
+// A relative core.hooksPath is resolved against the working tree root, which
+// is where git itself runs hooks from.
+func HooksDir(ctx context.Context) (string, error) {
+	if p, err := run(ctx, "config", "--type=path", "--get", "core.hooksPath"); err == nil && p != "" {
+		if filepath.IsAbs(p) {
+			return p, nil
+		}
+		top, err := TopLevel(ctx)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(top, p), nil
+	}
+	return run(ctx, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ApplyCached stages patch into the index without touching the working tree.
+func ApplyCached(ctx context.Context, patch string) error {
+	cmd := exec.CommandContext(ctx, "git", "apply", "--cached", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply --cached failed: %v\n%s", err, out.String())
+	}
+	return nil
+}
+
+// WriteTree records the current index as a tree object and returns its id.
+func WriteTree(ctx context.Context) (string, error) {
+	return run(ctx, "write-tree")
+}
+
+// ReadTree replaces the index with the contents of tree.
+func ReadTree(ctx context.Context, tree string) error {
+	_, err := run(ctx, "read-tree", tree)
+	return err
+}
+
+// ResetIndexToHead makes the index match HEAD (or empties it before the first commit).
+func ResetIndexToHead(ctx context.Context) error {
+	if _, err := ResolveRev(ctx, "HEAD"); err != nil {
+		_, err := run(ctx, "read-tree", "--empty")
+		return err
+	}
+	return ReadTree(ctx, "HEAD")
+}
@@ -0,0 +1,287 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// Commit is the subset of commit metadata gessage works with.
+type Commit struct {
+	Hash        string
+	Tree        string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  string // raw format ("<unix> <tz>"), suitable for GIT_AUTHOR_DATE
+	Message     string
+	// Paths lists the files the commit touched; only LogPaths fills it in.
+	Paths []string
+}
+
+// Subject returns the first line of the commit message.
+func (c Commit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return strings.TrimSpace(subject)
+}
+
+// Body returns the message without its subject line.
+func (c Commit) Body() string {
+	_, body, _ := strings.Cut(c.Message, "\n")
+	return strings.TrimSpace(body)
+}
+
+// logFormat separates fields with US and records with RS so that multi-line
+// messages survive parsing.
+const logFormat = "%H%x1f%T%x1f%P%x1f%an%x1f%ae%x1f%ad%x1f%B%x1e"
+
+// Log runs `git log` with args (revision ranges, ordering flags, pathspecs)
+// and returns the commits in the order git prints them.
+func Log(ctx context.Context, args ...string) ([]Commit, error) {
+	full := append([]string{"log", "--date=raw", "--format=" + logFormat}, args...)
+	out, err := run(ctx, full...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		f := strings.SplitN(rec, "\x1f", 7)
+		if len(f) != 7 {
+			return nil, fmt.Errorf("unexpected git log record: %q", rec)
+		}
+		commits = append(commits, commitFromFields(f))
+	}
+	return commits, nil
+}
+
+// LogPaths is Log with the paths each commit touched. Merge commits report
+// no paths.
+func LogPaths(ctx context.Context, args ...string) ([]Commit, error) {
+	// The record separator leads so the --name-only list git prints after
+	// the formatted header stays in the same record.
+	full := append([]string{"-c", "core.quotePath=false", "log", "--date=raw", "--name-only",
+		"--format=%x1e" + strings.TrimSuffix(logFormat, "%x1e") + "%x1f"}, args...)
+	out, err := run(ctx, full...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		if strings.TrimSpace(rec) == "" {
+			continue
+		}
+		f := strings.SplitN(rec, "\x1f", 8)
+		if len(f) != 8 {
+			return nil, fmt.Errorf("unexpected git log record: %q", rec)
+		}
+		c := commitFromFields(f[:7])
+		for _, p := range strings.Split(f[7], "\n") {
+			if p != "" {
+				c.Paths = append(c.Paths, diff.UnquotePath(p))
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+func commitFromFields(f []string) Commit {
+	return Commit{
+		Hash:        f[0],
+		Tree:        f[1],
+		Parents:     strings.Fields(f[2]),
+		AuthorName:  f[3],
+		AuthorEmail: f[4],
+		AuthorDate:  f[5],
+		Message:     strings.TrimSpace(f[6]),
+	}
+}
+
+// CommitDiff returns the patch a single commit introduces relative to its
+// first parent (or the empty tree for a root commit).
+func CommitDiff(ctx context.Context, rev string) (diff.Diff, error) {
+	d, err := runDiff(ctx, "diff-tree", "-M", "--root", "--no-commit-id", rev)
+	return withPost(d, err, rev+":")
+}
+
+// ResolveRev returns the full object id for rev.
+func ResolveRev(ctx context.Context, rev string) (string, error) {
+	out, err := run(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil || out == "" {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return out, nil
+}
+
+// Upstream returns the upstream ref of the current branch, or "" when none is configured.
+func Upstream(ctx context.Context) string {
+	out, err := run(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// IsAncestor reports whether commit a is reachable from commit b.
+func IsAncestor(ctx context.Context, a, b string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", a, b)
+	var out bytes.Buffer
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("git merge-base failed: %v\n%s", err, out.String())
+}
+
+// CommitTree creates a commit object for tree with the given parents and
+// message, preserving the original commit's authorship. It returns the new id.
+func CommitTree(ctx context.Context, orig Commit, parents []string, msg string) (string, error) {
+	args := []string{"commit-tree", orig.Tree}
+	for _, p := range parents {
+		args = append(args, "-p", p)
+	}
+	args = append(args, "-F", "-")
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+orig.AuthorName,
+		"GIT_AUTHOR_EMAIL="+orig.AuthorEmail,
+		"GIT_AUTHOR_DATE="+orig.AuthorDate,
+	)
+	cmd.Stdin = strings.NewReader(msg)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git commit-tree failed: %v\n%s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// UpdateRef moves ref to newID, failing if it no longer points at oldID.
+// Pass an empty oldID to create or overwrite the ref unconditionally.
+func UpdateRef(ctx context.Context, ref, newID, oldID, reason string) error {
+	args := []string{"update-ref", "-m", reason, ref, newID}
+	if oldID != "" {
+		args = append(args, oldID)
+	}
+	_, err := run(ctx, args...)
+	return err
+}
+
+// DefaultBranch returns the remote-tracking ref of remote's default branch
+// (e.g. "origin/main"), falling back to common branch names.
+func DefaultBranch(ctx context.Context, remote string) (string, error) {
+	if ref, err := run(ctx, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD"); err == nil && ref != "" {
+		return ref, nil
+	}
+	for _, candidate := range []string{remote + "/main", remote + "/master", "main", "master"} {
+		if _, err := ResolveRev(ctx, candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("cannot determine the default branch of %q; pass --base", remote)
+}
+
+// MergeBase returns the best common ancestor of a and b.
+func MergeBase(ctx context.Context, a, b string) (string, error) {
+	return run(ctx, "merge-base", a, b)
+}
+
+// DiffRevs returns the combined diff between two revisions.
+func DiffRevs(ctx context.Context, from, to string) (diff.Diff, error) {
+	d, err := runDiff(ctx, "diff", "-M", from, to)
+	return withPost(d, err, to+":")
+}
+
+// LatestTag returns the most recent tag reachable from rev, or "" when there is none.
+func LatestTag(ctx context.Context, rev string) string {
+	out, err := run(ctx, "describe", "--tags", "--abbrev=0", rev)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// CommitDate returns the committer date of rev as YYYY-MM-DD.
+func CommitDate(ctx context.Context, rev string) (string, error) {
+	return run(ctx, "log", "-1", "--format=%cs", rev)
+}
+
+// RemoteWebURL converts the fetch URL of remote into a browsable https URL,
+// e.g. git@github.com:owner/repo.git becomes https://github.com/owner/repo.
+func RemoteWebURL(ctx context.Context, remote string) (string, error) {
+	raw, err := run(ctx, "remote", "get-url", remote)
+	if err != nil {
+		return "", err
+	}
+	u := strings.TrimSuffix(raw, ".git")
+	switch {
+	case strings.HasPrefix(u, "https://"), strings.HasPrefix(u, "http://"):
+		if at := strings.Index(u, "@"); at >= 0 {
+			// drop embedded credentials
+			scheme, _, _ := strings.Cut(u, "://")
+			u = scheme + "://" + u[at+1:]
+		}
+	case strings.HasPrefix(u, "ssh://"):
+		u = strings.TrimPrefix(u, "ssh://")
+		if at := strings.Index(u, "@"); at >= 0 {
+			u = u[at+1:]
+		}
+		host, path, _ := strings.Cut(u, "/")
+		host, _, _ = strings.Cut(host, ":") // drop port
+		u = "https://" + host + "/" + path
+	case strings.Contains(u, ":"):
+		if at := strings.Index(u, "@"); at >= 0 {
+			u = u[at+1:]
+		}
+		u = "https://" + strings.Replace(u, ":", "/", 1)
+	default:
+		return "", fmt.Errorf("remote %s has no web URL (%s)", remote, raw)
+	}
+	return u, nil
+}
+
+// MergedTags lists the tags matching pattern that are reachable from rev.
+func MergedTags(ctx context.Context, pattern, rev string) ([]string, error) {
+	out, err := run(ctx, "tag", "--list", "--merged", rev, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// CreateAnnotatedTag tags HEAD with name, reading the tag message from msg.
+// The message is kept verbatim: git's default cleanup would strip Markdown
+// headings ("## Features") as comment lines.
+func CreateAnnotatedTag(ctx context.Context, name, msg string) error {
+	cmd := exec.CommandContext(ctx, "git", "tag", "-a", "--cleanup=verbatim", name, "-F", "-")
+	cmd.Stdin = strings.NewReader(msg)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git tag failed: %v\n%s", err, out.String())
+	}
+	return nil
+}
+
+// ResetSoft moves HEAD to rev, keeping the index and working tree.
+func ResetSoft(ctx context.Context, rev string) error {
+	_, err := run(ctx, "reset", "--soft", rev)
+	return err
+}
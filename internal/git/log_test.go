@@ -0,0 +1,78 @@
+package git
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCommitTreeKeepsTreeAndAuthor(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	writeFile(t, "b.txt", "b\n")
+	gitT(t, "add", "b.txt")
+	gitT(t, "commit", "-q", "-m", "wip", "--author", "Ada <ada@example.com>", "--date", "1700000000 +0200")
+
+	commits, err := Log(ctx, "-1", "HEAD")
+	if err != nil || len(commits) != 1 {
+		t.Fatalf("Log() = %v, %v", commits, err)
+	}
+	orig := commits[0]
+	id, err := CommitTree(ctx, orig, orig.Parents, "feat: add b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten, err := Log(ctx, "-1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rewritten[0]
+	if got.Message != "feat: add b" {
+		t.Errorf("Message = %q, want the new one", got.Message)
+	}
+	if got.Tree != orig.Tree || !reflect.DeepEqual(got.Parents, orig.Parents) {
+		t.Errorf("tree, parents = %s, %v, want %s, %v", got.Tree, got.Parents, orig.Tree, orig.Parents)
+	}
+	if got.AuthorName != "Ada" || got.AuthorEmail != "ada@example.com" || got.AuthorDate != "1700000000 +0200" {
+		t.Errorf("author = %s <%s> %s, want the original", got.AuthorName, got.AuthorEmail, got.AuthorDate)
+	}
+	if head := gitT(t, "rev-parse", "HEAD"); head != orig.Hash {
+		t.Errorf("CommitTree moved HEAD to %s", head)
+	}
+}
+
+func TestUpdateRef(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	first := gitT(t, "rev-parse", "HEAD")
+	commitFile(t, "b.txt", "b\n", "feat: add b")
+	second := gitT(t, "rev-parse", "HEAD")
+
+	// An empty old id creates the ref
+	if err := UpdateRef(ctx, "refs/gessage/backup/test", second, "", "backup"); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitT(t, "rev-parse", "refs/gessage/backup/test"); got != second {
+		t.Errorf("backup ref = %s, want %s", got, second)
+	}
+
+	// A stale old id is refused and leaves the ref alone
+	if err := UpdateRef(ctx, "HEAD", first, first, "stale"); err == nil {
+		t.Error("UpdateRef() with a stale old id succeeded")
+	}
+	if err := UpdateRef(ctx, "HEAD", first, second, "rewind"); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitT(t, "rev-parse", "HEAD"); got != first {
+		t.Errorf("HEAD = %s, want %s", got, first)
+	}
+	// HEAD is moved through the branch, with the reason in its reflog
+	if got := gitT(t, "rev-parse", "main"); got != first {
+		t.Errorf("main = %s, want %s", got, first)
+	}
+	if got := gitT(t, "reflog", "-1", "--format=%gs", "main"); got != "rewind" {
+		t.Errorf("reflog entry = %q, want %q", got, "rewind")
+	}
+}
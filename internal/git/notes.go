@@ -0,0 +1,37 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// NotesRef is where gessage records how a commit message was generated.
+const NotesRef = "refs/notes/gessage"
+
+// AddNote attaches note to rev under ref, replacing any existing note.
+func AddNote(ctx context.Context, ref, rev, note string) error {
+	cmd := exec.CommandContext(ctx, "git", "notes", "--ref="+ref, "add", "--force", "--file=-", rev)
+	cmd.Stdin = strings.NewReader(note)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git notes add failed: %v\n%s", err, out.String())
+	}
+	return nil
+}
+
+// Note returns the note attached to rev under ref, or "" when there is none.
+func Note(ctx context.Context, ref, rev string) (string, error) {
+	out, err := run(ctx, "notes", "--ref="+ref, "show", rev)
+	if err != nil {
+		if strings.Contains(err.Error(), "no note found") {
+			return "", nil
+		}
+		return "", err
+	}
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package git
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+
+	if note, err := Note(ctx, NotesRef, "HEAD"); err != nil || note != "" {
+		t.Errorf("Note() before adding one = %q, %v, want none", note, err)
+	}
+	if err := AddNote(ctx, NotesRef, "HEAD", "provider: a\n"); err != nil {
+		t.Fatal(err)
+	}
+	// Adding again replaces the note
+	if err := AddNote(ctx, NotesRef, "HEAD", "provider: b\n"); err != nil {
+		t.Fatal(err)
+	}
+	if note, err := Note(ctx, NotesRef, "HEAD"); err != nil || note != "provider: b" {
+		t.Errorf("Note() = %q, %v, want the replacement", note, err)
+	}
+	// Other refs, including git's default one, are left alone
+	if note, err := Note(ctx, "refs/notes/commits", "HEAD"); err != nil || note != "" {
+		t.Errorf("Note() under refs/notes/commits = %q, %v, want none", note, err)
+	}
+	if err := AddNote(ctx, NotesRef, "nope", "x\n"); err == nil {
+		t.Error("AddNote() on an unknown revision succeeded")
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotRepository is returned by Discover outside a git working tree.
+var ErrNotRepository = errors.New("not inside a git working tree (use -C <dir> to point at one)")
+
+// Repo describes the repository the current directory belongs to.
+type Repo struct {
+	// Root is the absolute path of the working tree root.
+	Root string
+	// GitDir is this working tree's git directory; for a linked worktree it
+	// lives under CommonDir/worktrees/<name>.
+	GitDir string
+	// CommonDir holds what all worktrees share: objects, refs, config and hooks.
+	CommonDir string
+}
+
+// IsLinkedWorktree reports whether Root was created with `git worktree add`.
+func (r Repo) IsLinkedWorktree() bool {
+	return r.GitDir != r.CommonDir
+}
+
+// Discover resolves the repository containing the current directory, which
+// may be any subdirectory of the working tree or of a linked worktree.
+func Discover(ctx context.Context) (Repo, error) {
+	out, err := run(ctx, "rev-parse", "--path-format=absolute", "--show-toplevel", "--git-dir", "--git-common-dir")
+	if err != nil {
+		return Repo{}, fmt.Errorf("%w\n%v", ErrNotRepository, err)
+	}
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 || lines[0] == "" {
+		return Repo{}, ErrNotRepository
+	}
+	return Repo{Root: lines[0], GitDir: lines[1], CommonDir: lines[2]}, nil
+}
+
+// TopLevel returns the absolute path of the working tree root.
+func TopLevel(ctx context.Context) (string, error) {
+	r, err := Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.Root, nil
+}
+
+// CurrentBranch returns the short name of the checked-out branch, or "" when HEAD is detached.
+func CurrentBranch(ctx context.Context) string {
+	out, err := run(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return out
+}
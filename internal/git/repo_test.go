@@ -0,0 +1,51 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	ctx := context.Background()
+	dir := testRepo(t)
+	commitFile(t, "sub/a.txt", "a\n", "feat: add a")
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir("sub")
+	r, err := Discover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Repo{Root: dir, GitDir: filepath.Join(dir, ".git"), CommonDir: filepath.Join(dir, ".git")}
+	if r != want || r.IsLinkedWorktree() {
+		t.Errorf("Discover() from a subdirectory = %+v, want %+v", r, want)
+	}
+
+	// A linked worktree has its own git directory and shares the common one
+	wt := filepath.Join(filepath.Dir(dir), filepath.Base(dir)+"-wt")
+	gitT(t, "worktree", "add", "-q", "-b", "wt", wt)
+	t.Chdir(filepath.Join(wt, "sub"))
+	if r, err = Discover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = Repo{Root: wt, GitDir: filepath.Join(dir, ".git", "worktrees", filepath.Base(wt)), CommonDir: filepath.Join(dir, ".git")}
+	if r != want || !r.IsLinkedWorktree() {
+		t.Errorf("Discover() in a linked worktree = %+v, want %+v", r, want)
+	}
+	if top, err := TopLevel(ctx); err != nil || top != wt {
+		t.Errorf("TopLevel() = %q, %v, want %q", top, err, wt)
+	}
+	if b := CurrentBranch(ctx); b != "wt" {
+		t.Errorf("CurrentBranch() = %q, want wt", b)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, err := Discover(ctx); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("Discover() outside a repository = %v, want ErrNotRepository", err)
+	}
+}
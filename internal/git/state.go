@@ -0,0 +1,173 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// Operation is a multi-step git command that is waiting for a commit.
+type Operation string
+
+const (
+	OpNone       Operation = ""
+	OpMerge      Operation = "merge"
+	OpSquash     Operation = "squash"
+	OpCherryPick Operation = "cherry-pick"
+	OpRevert     Operation = "revert"
+	OpRebase     Operation = "rebase"
+)
+
+// State describes the operation in progress, read from the files git keeps
+// in the repository directory (MERGE_HEAD, SQUASH_MSG, CHERRY_PICK_HEAD,
+// REVERT_HEAD, ...).
+type State struct {
+	Op Operation
+	// Head is the other side of the operation: the first MERGE_HEAD for
+	// merges, the commit being picked, reverted or rebased otherwise.
+	Head string
+	// Heads lists every MERGE_HEAD entry; an octopus merge has several.
+	Heads []string
+	// PreparedMessage is the message git prepared (MERGE_MSG, SQUASH_MSG or
+	// the message of the commit being rebased) with comment lines removed.
+	PreparedMessage string
+	// Conflicts lists the paths git reported as conflicted.
+	Conflicts []string
+}
+
+// DetectState inspects the repository for a merge, squash, cherry-pick,
+// revert or rebase that the next commit would conclude.
+func DetectState(ctx context.Context) (State, error) {
+	read := func(name string) (string, bool, error) {
+		p, err := run(ctx, "rev-parse", "--path-format=absolute", "--git-path", name)
+		if err != nil {
+			return "", false, err
+		}
+		b, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return string(b), true, nil
+	}
+	exists := func(name string) bool {
+		p, err := run(ctx, "rev-parse", "--path-format=absolute", "--git-path", name)
+		if err != nil {
+			return false
+		}
+		_, err = os.Stat(p)
+		return err == nil
+	}
+
+	var st State
+	if head, ok, err := read("MERGE_HEAD"); err != nil {
+		return st, err
+	} else if ok {
+		st.Op = OpMerge
+		st.Heads = strings.Fields(head)
+		st.Head = firstField(head)
+		msg, _, err := read("MERGE_MSG")
+		if err != nil {
+			return st, err
+		}
+		st.PreparedMessage = stripComments(msg)
+		st.Conflicts = conflictsFromMessage(msg)
+		return st, nil
+	}
+	if head, ok, err := read("CHERRY_PICK_HEAD"); err != nil {
+		return st, err
+	} else if ok {
+		st.Op = OpCherryPick
+		st.Head = firstField(head)
+		msg, _, err := read("MERGE_MSG")
+		if err != nil {
+			return st, err
+		}
+		st.Conflicts = conflictsFromMessage(msg)
+		return st, nil
+	}
+	if head, ok, err := read("REVERT_HEAD"); err != nil {
+		return st, err
+	} else if ok {
+		st.Op = OpRevert
+		st.Head = firstField(head)
+		msg, _, err := read("MERGE_MSG")
+		if err != nil {
+			return st, err
+		}
+		st.PreparedMessage = stripComments(msg)
+		st.Conflicts = conflictsFromMessage(msg)
+		return st, nil
+	}
+	if exists("rebase-merge") || exists("rebase-apply") {
+		st.Op = OpRebase
+		if head, ok, _ := read("REBASE_HEAD"); ok {
+			st.Head = firstField(head)
+		}
+		if msg, ok, _ := read("rebase-merge/message"); ok {
+			st.PreparedMessage = stripComments(msg)
+		}
+		return st, nil
+	}
+	if msg, ok, err := read("SQUASH_MSG"); err != nil {
+		return st, err
+	} else if ok {
+		st.Op = OpSquash
+		st.PreparedMessage = stripComments(msg)
+		return st, nil
+	}
+	return st, nil
+}
+
+// MessageOf returns the full commit message of rev.
+func MessageOf(ctx context.Context, rev string) (string, error) {
+	return run(ctx, "log", "-1", "--format=%B", rev)
+}
+
+// DiffCached returns the diff between rev and the index, optionally limited to paths.
+func DiffCached(ctx context.Context, rev string, paths ...string) (diff.Diff, error) {
+	d, err := runDiff(ctx, append([]string{"diff", "--cached", rev, "--"}, paths...)...)
+	return withPost(d, err, ":")
+}
+
+func firstField(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// stripComments drops '#' comment lines, as `git commit` does by default.
+func stripComments(msg string) string {
+	var out []string
+	for _, ln := range strings.Split(msg, "\n") {
+		if strings.HasPrefix(ln, "#") {
+			continue
+		}
+		out = append(out, ln)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// conflictsFromMessage reads the "# Conflicts:" list git appends to MERGE_MSG.
+func conflictsFromMessage(msg string) []string {
+	var files []string
+	in := false
+	for _, ln := range strings.Split(msg, "\n") {
+		t := strings.TrimSpace(strings.TrimPrefix(ln, "#"))
+		switch {
+		case t == "Conflicts:":
+			in = true
+		case in && strings.HasPrefix(ln, "#\t"):
+			files = append(files, t)
+		case in:
+			in = false
+		}
+	}
+	return files
+}
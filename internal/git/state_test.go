@@ -0,0 +1,163 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// divergedRepo leaves main and topic each with a commit changing a.txt
+// differently, so merging, picking or rebasing one onto the other conflicts.
+// topic also adds b.txt in a second commit, which applies cleanly.
+func divergedRepo(t *testing.T) {
+	t.Helper()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	gitT(t, "checkout", "-q", "-b", "topic")
+	commitFile(t, "a.txt", "topic\n", "fix: change a on topic")
+	commitFile(t, "b.txt", "b\n", "feat: add b")
+	gitT(t, "checkout", "-q", "main")
+	commitFile(t, "a.txt", "main\n", "fix: change a on main")
+}
+
+// gitFails runs a git command that is expected to stop with a conflict.
+func gitFails(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", args...).CombinedOutput(); err == nil {
+		t.Fatalf("git %s succeeded, want it to stop\n%s", strings.Join(args, " "), out)
+	}
+}
+
+func TestDetectState(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T)
+		op        Operation
+		head      string // rev-parse'd to the expected Head
+		heads     int
+		prepared  string // prefix of PreparedMessage
+		conflicts []string
+	}{
+		{name: "clean", setup: func(t *testing.T) {}},
+		{
+			name:      "merge with conflicts",
+			setup:     func(t *testing.T) { gitFails(t, "merge", "topic") },
+			op:        OpMerge,
+			head:      "topic",
+			heads:     1,
+			prepared:  "Merge branch 'topic'",
+			conflicts: []string{"a.txt"},
+		},
+		{
+			name: "octopus merge",
+			setup: func(t *testing.T) {
+				gitT(t, "checkout", "-q", "-b", "other", "main~1")
+				commitFile(t, "c.txt", "c\n", "feat: add c")
+				gitT(t, "checkout", "-q", "-b", "third", "main~1")
+				commitFile(t, "d.txt", "d\n", "feat: add d")
+				gitT(t, "checkout", "-q", "main")
+				gitT(t, "merge", "-q", "--no-commit", "other", "third")
+			},
+			op:       OpMerge,
+			head:     "other",
+			heads:    2,
+			prepared: "Merge branches 'other' and 'third'",
+		},
+		{
+			name:      "cherry-pick",
+			setup:     func(t *testing.T) { gitFails(t, "cherry-pick", "topic~1") },
+			op:        OpCherryPick,
+			head:      "topic~1",
+			conflicts: []string{"a.txt"},
+		},
+		{
+			name:     "revert",
+			setup:    func(t *testing.T) { gitT(t, "revert", "--no-commit", "HEAD") },
+			op:       OpRevert,
+			head:     "HEAD",
+			prepared: `Revert "fix: change a on main"`,
+		},
+		{
+			name: "rebase",
+			setup: func(t *testing.T) {
+				gitT(t, "checkout", "-q", "topic")
+				gitFails(t, "rebase", "--merge", "main")
+			},
+			op:       OpRebase,
+			head:     "topic~1",
+			prepared: "fix: change a on topic",
+		},
+		{
+			name: "rebase apply backend",
+			setup: func(t *testing.T) {
+				gitT(t, "checkout", "-q", "topic")
+				gitFails(t, "rebase", "--apply", "main")
+			},
+			op:   OpRebase,
+			head: "topic~1",
+		},
+		{
+			name: "squash",
+			setup: func(t *testing.T) {
+				gitT(t, "checkout", "-q", "-b", "other", "main~1")
+				commitFile(t, "c.txt", "c\n", "feat: add c")
+				gitT(t, "checkout", "-q", "main")
+				gitT(t, "merge", "-q", "--squash", "other")
+			},
+			op:       OpSquash,
+			prepared: "Squashed commit of the following:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			divergedRepo(t)
+			tt.setup(t)
+			want := ""
+			if tt.head != "" {
+				want = gitT(t, "rev-parse", tt.head)
+			}
+			st, err := DetectState(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.Op != tt.op || st.Head != want || len(st.Heads) != tt.heads {
+				t.Errorf("DetectState() = %q, head %q, %d heads, want %q, head %q, %d heads", st.Op, st.Head, len(st.Heads), tt.op, want, tt.heads)
+			}
+			if !strings.HasPrefix(st.PreparedMessage, tt.prepared) || (tt.prepared == "") != (st.PreparedMessage == "") {
+				t.Errorf("PreparedMessage = %q, want it to start with %q", st.PreparedMessage, tt.prepared)
+			}
+			if strings.Contains(st.PreparedMessage, "\n#") {
+				t.Errorf("PreparedMessage keeps comment lines: %q", st.PreparedMessage)
+			}
+			if !reflect.DeepEqual(st.Conflicts, tt.conflicts) {
+				t.Errorf("Conflicts = %q, want %q", st.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestDiffCachedAgainstMergeHead(t *testing.T) {
+	divergedRepo(t)
+	gitT(t, "merge", "-q", "--no-commit", "-X", "ours", "topic")
+
+	// Against HEAD the merge brings in b.txt; against topic it keeps main's a.txt
+	ours, err := DiffCached(context.Background(), "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(ours.Paths(), " "); got != "b.txt" {
+		t.Errorf("DiffCached(HEAD) covers %q, want b.txt", got)
+	}
+	theirs, err := DiffCached(context.Background(), "MERGE_HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(theirs.Paths(), " "); got != "a.txt" {
+		t.Errorf("DiffCached(MERGE_HEAD) covers %q, want a.txt", got)
+	}
+	if msg, err := MessageOf(context.Background(), "MERGE_HEAD"); err != nil || msg != "feat: add b" {
+		t.Errorf("MessageOf(MERGE_HEAD) = %q, %v", msg, err)
+	}
+}
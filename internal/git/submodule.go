@@ -0,0 +1,113 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/diff"
+)
+
+// maxSubmoduleSubjects caps how many commit subjects are listed per submodule.
+const maxSubmoduleSubjects = 30
+
+// DescribeSubmodules replaces the opaque "Subproject commit <sha>" hunks of
+// submodule pointer changes with the subjects of the commits between the old
+// and new pointer, in the style of `git diff --submodule=log`:
+//
+//	Submodule vendor/lib 1a2b3c4..5d6e7f8:
+//	  > feat: add retries
+//	  < fix: reverted commit
+//
+// The result is meant for prompts, not for `git apply`. Submodules that are
+// not checked out keep their original hunk.
+func DescribeSubmodules(ctx context.Context, d diff.Diff) diff.Diff {
+	root := ""
+	out := diff.Diff{Omitted: d.Omitted}
+	for _, f := range d.Files {
+		if !f.Submodule {
+			out.Files = append(out.Files, f)
+			continue
+		}
+		if root == "" {
+			var err error
+			if root, err = TopLevel(ctx); err != nil {
+				return d
+			}
+		}
+		oldSHA, newSHA, ok := submodulePointers(f.Patch())
+		if !ok {
+			out.Files = append(out.Files, f)
+			continue
+		}
+		summary, err := submoduleLog(ctx, filepath.Join(root, f.Path()), oldSHA, newSHA)
+		if err != nil {
+			out.Files = append(out.Files, f)
+			continue
+		}
+		f.Header = fmt.Sprintf("Submodule %s %s..%s:\n%s", f.Path(), short(oldSHA), short(newSHA), summary)
+		f.Hunks = nil
+		out.Files = append(out.Files, f)
+	}
+	return out
+}
+
+// submodulePointers extracts the old and new commit of a gitlink patch.
+// A missing side (submodule added or removed) is returned as "".
+func submodulePointers(file string) (oldSHA, newSHA string, ok bool) {
+	for _, ln := range strings.Split(file, "\n") {
+		switch {
+		case strings.HasPrefix(ln, "-Subproject commit "):
+			oldSHA = strings.TrimSuffix(strings.TrimPrefix(ln, "-Subproject commit "), "-dirty")
+		case strings.HasPrefix(ln, "+Subproject commit "):
+			newSHA = strings.TrimSuffix(strings.TrimPrefix(ln, "+Subproject commit "), "-dirty")
+		}
+	}
+	return oldSHA, newSHA, oldSHA != "" || newSHA != ""
+}
+
+// submoduleLog lists the commits between two pointers of the submodule
+// checked out at dir, marking added commits ">" and rewound ones "<".
+func submoduleLog(ctx context.Context, dir, oldSHA, newSHA string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return "", fmt.Errorf("submodule %s is not checked out", dir)
+	}
+	var args []string
+	switch {
+	case newSHA == "":
+		return "  (submodule removed)\n", nil
+	case oldSHA == "":
+		args = []string{"log", "--format=> %s", newSHA}
+	default:
+		args = []string{"log", "--left-right", "--format=%m %s", oldSHA + "..." + newSHA}
+	}
+	out, err := runIn(ctx, dir, args...)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "  (no commits between the two pointers)\n", nil
+	}
+	lines := strings.Split(out, "\n")
+	var b strings.Builder
+	for i, ln := range lines {
+		if i == maxSubmoduleSubjects {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(lines)-i)
+			break
+		}
+		b.WriteString("  " + ln + "\n")
+	}
+	return b.String(), nil
+}
+
+func short(sha string) string {
+	if sha == "" {
+		return "0000000"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withSubmodule adds a repository with one commit as the submodule "lib" and
+// returns the id of that commit.
+func withSubmodule(t *testing.T) string {
+	t.Helper()
+	lib := filepath.Join(t.TempDir(), "lib")
+	gitT(t, "init", "-q", "-b", "main", lib)
+	gitT(t, "-C", lib, "commit", "-q", "--allow-empty", "-m", "feat: one")
+	gitT(t, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	gitT(t, "commit", "-q", "-m", "chore: add lib")
+	return gitT(t, "-C", "lib", "rev-parse", "HEAD")
+}
+
+func TestDescribeSubmodules(t *testing.T) {
+	ctx := context.Background()
+	testRepo(t)
+	commitFile(t, "a.txt", "a\n", "feat: add a")
+	one := withSubmodule(t)
+	gitT(t, "-C", "lib", "commit", "-q", "--allow-empty", "-m", "feat: two")
+	gitT(t, "-C", "lib", "commit", "-q", "--allow-empty", "-m", "fix: three")
+	three := gitT(t, "-C", "lib", "rev-parse", "HEAD")
+	writeFile(t, "a.txt", "b\n")
+	gitT(t, "add", "lib", "a.txt")
+
+	d, err := GetStagedDiff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := DescribeSubmodules(ctx, d)
+	if len(got.Files) != 2 {
+		t.Fatalf("DescribeSubmodules() kept %d files, want 2", len(got.Files))
+	}
+	if got.Files[0].Patch() != d.Files[0].Patch() {
+		t.Errorf("a.txt changed:\n%s", got.Files[0].Patch())
+	}
+	want := "Submodule lib " + one[:7] + ".." + three[:7] + ":\n  > fix: three\n  > feat: two\n"
+	if sub := got.Files[1]; sub.Header != want || sub.Hunks != nil {
+		t.Errorf("submodule described as:\n%s\nwant:\n%s", sub.Patch(), want)
+	}
+
+	// Moving the pointer back lists the rewound commits
+	gitT(t, "commit", "-q", "-m", "chore: bump lib")
+	gitT(t, "-C", "lib", "checkout", "-q", one)
+	gitT(t, "add", "lib")
+	if d, err = GetStagedDiff(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := DescribeSubmodules(ctx, d).Files[0].Patch(); !strings.HasSuffix(got, ":\n  < fix: three\n  < feat: two\n") {
+		t.Errorf("rewound submodule described as:\n%s", got)
+	}
+
+	// A submodule that is not checked out keeps its hunk
+	if err := os.Remove(filepath.Join("lib", ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if got := DescribeSubmodules(ctx, d).Files[0].Patch(); got != d.Files[0].Patch() {
+		t.Errorf("submodule without a checkout described as:\n%s", got)
+	}
+}
@@ -0,0 +1,176 @@
+// Package release computes semantic version bumps from Conventional Commits.
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ispooya/gessage-cli/internal/format"
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+// Version is a semantic version with an optional "<id>.<n>" pre-release part.
+type Version struct {
+	Major, Minor, Patch int
+	PreID               string // e.g. "rc"; empty for stable releases
+	PreNum              int
+	// Pre is the pre-release as written ("rc.4", "beta"), so String gives
+	// back the tag it was parsed from; empty for computed versions.
+	Pre string
+}
+
+var semverRe = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-(([0-9A-Za-z-]+)(?:\.(\d+))?))?$`)
+
+// Parse reads "1.2.3" or "1.2.3-rc.4" (without any tag prefix).
+func Parse(s string) (Version, bool) {
+	m := semverRe.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, false
+	}
+	v := Version{Pre: m[4], PreID: m[5]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	if m[6] != "" {
+		v.PreNum, _ = strconv.Atoi(m[6])
+	}
+	return v, true
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	switch {
+	case v.Pre != "":
+		s += "-" + v.Pre
+	case v.PreID != "":
+		s += fmt.Sprintf("-%s.%d", v.PreID, v.PreNum)
+	}
+	return s
+}
+
+// IsPrerelease reports whether v carries a pre-release suffix.
+func (v Version) IsPrerelease() bool { return v.PreID != "" }
+
+// Less orders versions per semver precedence; pre-releases sort before
+// the corresponding stable release.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	if v.Patch != o.Patch {
+		return v.Patch < o.Patch
+	}
+	if v.PreID == "" || o.PreID == "" {
+		return v.PreID != "" && o.PreID == ""
+	}
+	if v.PreID != o.PreID {
+		return v.PreID < o.PreID
+	}
+	return v.PreNum < o.PreNum
+}
+
+// Bump is the size of a release increment.
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	return [...]string{"none", "patch", "minor", "major"}[b]
+}
+
+// Classify returns the largest bump required by commits: breaking changes
+// are major, feat is minor, fix and perf are patch; everything else is none.
+func Classify(commits []git.Commit) Bump {
+	bump := BumpNone
+	for _, c := range commits {
+		cc, ok := format.ParseConventional(c.Message)
+		if !ok {
+			continue
+		}
+		b := BumpNone
+		switch {
+		case cc.Breaking:
+			b = BumpMajor
+		case cc.Type == "feat":
+			b = BumpMinor
+		case cc.Type == "fix" || cc.Type == "perf":
+			b = BumpPatch
+		}
+		if b > bump {
+			bump = b
+		}
+	}
+	return bump
+}
+
+// Next applies bump to the stable version base.
+func Next(base Version, bump Bump) Version {
+	next := Version{Major: base.Major, Minor: base.Minor, Patch: base.Patch}
+	switch bump {
+	case BumpMajor:
+		next = Version{Major: base.Major + 1}
+	case BumpMinor:
+		next = Version{Major: base.Major, Minor: base.Minor + 1}
+	case BumpPatch:
+		next.Patch++
+	}
+	return next
+}
+
+// Tag is a version tag found in the repository.
+type Tag struct {
+	Name    string
+	Version Version
+}
+
+// ParseTags keeps the tags that are prefix followed by a semantic version.
+func ParseTags(names []string, prefix string) []Tag {
+	var tags []Tag
+	for _, n := range names {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		if v, ok := Parse(strings.TrimPrefix(n, prefix)); ok {
+			tags = append(tags, Tag{Name: n, Version: v})
+		}
+	}
+	return tags
+}
+
+// LatestStable returns the highest stable tag, if any.
+func LatestStable(tags []Tag) (Tag, bool) {
+	var best Tag
+	found := false
+	for _, t := range tags {
+		if t.Version.IsPrerelease() {
+			continue
+		}
+		if !found || best.Version.Less(t.Version) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+// NextPrerelease numbers a pre-release of target with id, continuing after
+// the highest existing "<target>-<id>.N" tag.
+func NextPrerelease(tags []Tag, target Version, id string) Version {
+	next := Version{Major: target.Major, Minor: target.Minor, Patch: target.Patch, PreID: id, PreNum: 1}
+	for _, t := range tags {
+		v := t.Version
+		if v.Major == target.Major && v.Minor == target.Minor && v.Patch == target.Patch && v.PreID == id && v.PreNum >= next.PreNum {
+			next.PreNum = v.PreNum + 1
+		}
+	}
+	return next
+}
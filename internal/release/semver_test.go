@@ -0,0 +1,113 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/ispooya/gessage-cli/internal/git"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		ok   bool
+	}{
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, true},
+		{"0.10.0-rc.4", Version{Minor: 10, PreID: "rc", PreNum: 4, Pre: "rc.4"}, true},
+		{"1.0.0-beta", Version{Major: 1, PreID: "beta", Pre: "beta"}, true},
+		{"2.0.0-alpha-2", Version{Major: 2, PreID: "alpha-2", Pre: "alpha-2"}, true},
+		{"1.2", Version{}, false},
+		{"v1.2.3", Version{}, false},
+		{"1.2.3-", Version{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestLess(t *testing.T) {
+	// Each version sorts before the next.
+	order := []string{"0.9.9", "1.0.0-alpha.1", "1.0.0-alpha.2", "1.0.0-beta", "1.0.0-beta.1", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := range order {
+		for j := range order {
+			a, _ := Parse(order[i])
+			b, _ := Parse(order[j])
+			if got := a.Less(b); got != (i < j) {
+				t.Errorf("%s.Less(%s) = %v", order[i], order[j], got)
+			}
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	commits := func(msgs ...string) []git.Commit {
+		var cs []git.Commit
+		for _, m := range msgs {
+			cs = append(cs, git.Commit{Message: m})
+		}
+		return cs
+	}
+	tests := []struct {
+		name    string
+		commits []git.Commit
+		want    Bump
+	}{
+		{"empty", nil, BumpNone},
+		{"chores only", commits("chore: bump deps", "docs: typo", "Merge branch 'x'"), BumpNone},
+		{"fix", commits("docs: typo", "fix: nil check"), BumpPatch},
+		{"perf", commits("perf: cache"), BumpPatch},
+		{"feat beats fix", commits("fix: a", "feat(ui): b", "fix: c"), BumpMinor},
+		{"bang", commits("feat: a", "refactor!: drop v1"), BumpMajor},
+		{"breaking footer", commits("chore: x\n\nBREAKING CHANGE: node 20 required"), BumpMajor},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.commits); got != tt.want {
+			t.Errorf("%s: Classify() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		base string
+		bump Bump
+		want string
+	}{
+		{"1.2.3", BumpNone, "1.2.3"},
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"0.0.0", BumpMinor, "0.1.0"},
+		{"1.2.3-rc.1", BumpPatch, "1.2.4"},
+	}
+	for _, tt := range tests {
+		base, _ := Parse(tt.base)
+		if got := Next(base, tt.bump).String(); got != tt.want {
+			t.Errorf("Next(%s, %s) = %s, want %s", tt.base, tt.bump, got, tt.want)
+		}
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tags := ParseTags([]string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2", "v1.1.0-beta", "v1.2.0-rc.7", "other-1.1.0-rc.9", "v1.1.0-rc.x"}, "v")
+	tests := []struct {
+		target, id, want string
+	}{
+		{"1.1.0", "rc", "1.1.0-rc.3"},
+		{"1.1.0", "beta", "1.1.0-beta.1"},
+		{"1.1.0", "alpha", "1.1.0-alpha.1"},
+		{"2.0.0", "rc", "2.0.0-rc.1"},
+	}
+	for _, tt := range tests {
+		target, _ := Parse(tt.target)
+		if got := NextPrerelease(tags, target, tt.id).String(); got != tt.want {
+			t.Errorf("NextPrerelease(%s, %s) = %s, want %s", tt.target, tt.id, got, tt.want)
+		}
+	}
+}
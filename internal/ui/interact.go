@@ -3,15 +3,19 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 )
 
+// stdin is shared across ReadChoice calls so that buffered input (e.g. piped
+// answers for several prompts) is not lost between reads.
+var stdin = bufio.NewReader(os.Stdin)
+
 // ReadChoice reads a short token from stdin.
 func ReadChoice() (string, error) {
-	in := bufio.NewReader(os.Stdin)
-	s, err := in.ReadString('\n')
+	s, err := stdin.ReadString('\n')
 	if err != nil {
 		return "", err
 	}
@@ -56,16 +60,21 @@ func readMultilineWithTerminator(initial string) (string, error) {
 	fmt.Println("----------------")
 	fmt.Println("(Edit lines below; type '.' on its own line to finish)")
 	var lines []string
-	sc := bufio.NewScanner(os.Stdin)
-	for sc.Scan() {
-		t := sc.Text()
-		if strings.TrimSpace(t) == "." {
+	for {
+		// Read through the shared reader so answers piped for later prompts
+		// stay buffered there
+		t, err := stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		t = strings.TrimRight(t, "\r\n")
+		if strings.TrimSpace(t) == "." || (err == io.EOF && t == "") {
 			break
 		}
 		lines = append(lines, t)
-	}
-	if err := sc.Err(); err != nil {
-		return "", err
+		if err == io.EOF {
+			break
+		}
 	}
 	txt := strings.Join(lines, "\n")
 	if strings.TrimSpace(txt) == "" {
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TerminalWidth returns the width advertised by $COLUMNS, or a sane default.
+func TerminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n >= 40 {
+		return n
+	}
+	return 120
+}
+
+// SideBySide renders two texts in adjacent columns, wrapping long lines so
+// each column fits in half of width.
+func SideBySide(leftLabel, left, rightLabel, right string, width int) string {
+	col := (width - 3) / 2
+	if col < 10 {
+		col = 10
+	}
+	l := append([]string{leftLabel, strings.Repeat("-", col)}, wrapColumn(left, col)...)
+	r := append([]string{rightLabel, strings.Repeat("-", col)}, wrapColumn(right, col)...)
+	rows := len(l)
+	if len(r) > rows {
+		rows = len(r)
+	}
+	var b strings.Builder
+	for i := 0; i < rows; i++ {
+		var a, c string
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(r) {
+			c = r[i]
+		}
+		b.WriteString(a)
+		b.WriteString(strings.Repeat(" ", col-utf8.RuneCountInString(a)))
+		b.WriteString(" | ")
+		b.WriteString(c)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// wrapColumn hard-wraps every line of s to at most width runes.
+func wrapColumn(s string, width int) []string {
+	var out []string
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		runes := []rune(line)
+		for len(runes) > width {
+			out = append(out, string(runes[:width]))
+			runes = runes[width:]
+		}
+		out = append(out, string(runes))
+	}
+	return out
+}